@@ -0,0 +1,6 @@
+
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS tasks_action_time_id_idx ON tasks (action_time, id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS tasks_action_time_id_idx;
@@ -0,0 +1,50 @@
+
+-- +migrate Up
+-- +sqlite skip
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION tasks_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce((SELECT string_agg(objective_name, ' ') FROM objectives WHERE task_id = NEW.id), '')), 'B');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION objectives_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    -- touching the task makes tasks_search_vector_update rebuild its vector
+    IF TG_OP = 'DELETE' THEN
+        UPDATE tasks SET search_vector = NULL WHERE id = OLD.task_id;
+    ELSE
+        UPDATE tasks SET search_vector = NULL WHERE id = NEW.task_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER tasks_search_vector_trigger
+    BEFORE INSERT OR UPDATE ON tasks
+    FOR EACH ROW EXECUTE PROCEDURE tasks_search_vector_update();
+
+CREATE TRIGGER objectives_search_vector_trigger
+    AFTER INSERT OR UPDATE OR DELETE ON objectives
+    FOR EACH ROW EXECUTE PROCEDURE objectives_search_vector_update();
+
+CREATE INDEX IF NOT EXISTS tasks_search_vector_idx ON tasks USING GIN (search_vector);
+
+UPDATE tasks SET search_vector = NULL;
+-- +sqlite end
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS objectives_search_vector_trigger ON objectives;
+DROP TRIGGER IF EXISTS tasks_search_vector_trigger ON tasks;
+DROP FUNCTION IF EXISTS objectives_search_vector_update();
+DROP FUNCTION IF EXISTS tasks_search_vector_update();
+DROP INDEX IF EXISTS tasks_search_vector_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,8 @@
+
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN deleted_at timestamp NULL;
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON tasks (deleted_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+ALTER TABLE tasks DROP COLUMN deleted_at;
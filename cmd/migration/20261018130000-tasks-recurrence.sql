@@ -0,0 +1,12 @@
+
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN recurrence VARCHAR(255) NULL;
+ALTER TABLE tasks ADD COLUMN recurrence_start timestamp NULL;
+ALTER TABLE tasks ADD COLUMN has_next BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS tasks_due_recurring_idx ON tasks (action_time, id) WHERE recurrence IS NOT NULL AND has_next = FALSE AND deleted_at IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS tasks_due_recurring_idx;
+ALTER TABLE tasks DROP COLUMN has_next;
+ALTER TABLE tasks DROP COLUMN recurrence_start;
+ALTER TABLE tasks DROP COLUMN recurrence;
@@ -0,0 +1,19 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS reminders
+(
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL,
+    offset_seconds BIGINT NOT NULL,
+    action_time timestamp NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at timestamp,
+    sent_at timestamp NULL,
+
+    FOREIGN KEY ("task_id") REFERENCES "tasks" ("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS reminders_task_offset_action_time_idx ON reminders (task_id, offset_seconds, action_time);
+
+-- +migrate Down
+DROP TABLE IF EXISTS reminders;
@@ -0,0 +1,23 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS outbox_events
+(
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    task_id BIGINT NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    locked_until timestamp NOT NULL,
+    created_at timestamp,
+    published_at timestamp NULL
+);
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (id) WHERE published_at IS NULL;
+
+ALTER TABLE webhook_deliveries ADD COLUMN event_id BIGINT NOT NULL DEFAULT 0;
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_webhook_event_idx ON webhook_deliveries (webhook_id, event_id) WHERE event_id > 0;
+
+-- +migrate Down
+DROP INDEX IF EXISTS webhook_deliveries_webhook_event_idx;
+ALTER TABLE webhook_deliveries DROP COLUMN event_id;
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,17 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    version BIGINT NOT NULL,
+    before TEXT NULL,
+    after TEXT NULL,
+    created_at timestamp
+);
+CREATE INDEX IF NOT EXISTS audit_log_task_id_idx ON audit_log (task_id, id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,25 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS users
+(
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at timestamp,
+    updated_at timestamp
+);
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users (email);
+
+ALTER TABLE tasks ADD COLUMN owner_id BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS tasks_owner_id_idx ON tasks (owner_id);
+ALTER TABLE webhooks ADD COLUMN owner_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE audit_log ADD COLUMN owner_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE outbox_events ADD COLUMN owner_id BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE outbox_events DROP COLUMN owner_id;
+ALTER TABLE audit_log DROP COLUMN owner_id;
+ALTER TABLE webhooks DROP COLUMN owner_id;
+DROP INDEX IF EXISTS tasks_owner_id_idx;
+ALTER TABLE tasks DROP COLUMN owner_id;
+DROP TABLE IF EXISTS users;
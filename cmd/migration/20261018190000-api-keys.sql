@@ -0,0 +1,19 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scope VARCHAR(16) NOT NULL,
+    expires_at timestamp NULL,
+    last_used_at timestamp NULL,
+    created_at timestamp
+);
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_key_hash_idx ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS api_keys_owner_id_idx ON api_keys (owner_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS api_keys;
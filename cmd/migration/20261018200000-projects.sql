@@ -0,0 +1,19 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS projects
+(
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at timestamp,
+    updated_at timestamp
+);
+CREATE INDEX IF NOT EXISTS projects_owner_id_idx ON projects (owner_id);
+
+ALTER TABLE tasks ADD COLUMN project_id BIGINT NULL;
+CREATE INDEX IF NOT EXISTS tasks_project_id_idx ON tasks (project_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS tasks_project_id_idx;
+ALTER TABLE tasks DROP COLUMN project_id;
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,22 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tags
+(
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_at timestamp
+);
+CREATE UNIQUE INDEX IF NOT EXISTS tags_owner_id_name_idx ON tags (owner_id, name);
+
+CREATE TABLE IF NOT EXISTS task_tags
+(
+    task_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS task_tags_task_id_tag_id_idx ON task_tags (task_id, tag_id);
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx ON task_tags (tag_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS task_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,10 @@
+
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN due_at timestamp NULL;
+CREATE INDEX IF NOT EXISTS tasks_priority_due_at_idx ON tasks (priority, due_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS tasks_priority_due_at_idx;
+ALTER TABLE tasks DROP COLUMN due_at;
+ALTER TABLE tasks DROP COLUMN priority;
@@ -0,0 +1,8 @@
+
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN parent_id BIGINT NULL;
+CREATE INDEX IF NOT EXISTS tasks_parent_id_idx ON tasks (parent_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS tasks_parent_id_idx;
+ALTER TABLE tasks DROP COLUMN parent_id;
@@ -0,0 +1,12 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS task_dependencies
+(
+    task_id BIGINT NOT NULL,
+    blocker_id BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS task_dependencies_task_id_blocker_id_idx ON task_dependencies (task_id, blocker_id);
+CREATE INDEX IF NOT EXISTS task_dependencies_blocker_id_idx ON task_dependencies (blocker_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS task_dependencies;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE outbox_events ADD COLUMN dead_lettered_at timestamp NULL;
+DROP INDEX IF EXISTS outbox_events_unpublished_idx;
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS outbox_events_unpublished_idx;
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (id) WHERE published_at IS NULL;
+ALTER TABLE outbox_events DROP COLUMN dead_lettered_at;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN next_id BIGINT NULL;
+
+-- +migrate Down
+ALTER TABLE tasks DROP COLUMN next_id;
@@ -0,0 +1,10 @@
+-- +migrate Up
+ALTER TABLE reminders ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE reminders ADD COLUMN next_attempt_at timestamp NULL;
+UPDATE reminders SET attempts = 1, next_attempt_at = CURRENT_TIMESTAMP WHERE status <> 'sent';
+CREATE INDEX IF NOT EXISTS reminders_next_attempt_at_idx ON reminders (next_attempt_at, id) WHERE next_attempt_at IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS reminders_next_attempt_at_idx;
+ALTER TABLE reminders DROP COLUMN next_attempt_at;
+ALTER TABLE reminders DROP COLUMN attempts;
@@ -0,0 +1,23 @@
+-- +migrate Up
+-- the tasks left in a project deleted meanwhile are moved out of it
+UPDATE tasks SET project_id = NULL WHERE project_id IS NOT NULL AND project_id NOT IN (SELECT id FROM projects);
+
+-- +sqlite skip
+ALTER TABLE tasks ADD CONSTRAINT tasks_project_id_fkey FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE RESTRICT;
+-- +sqlite end
+
+-- sqlite can't add a foreign key to an existing table, triggers enforce the same key
+-- +sqlite only
+-- CREATE TRIGGER IF NOT EXISTS tasks_project_id_insert_fkey BEFORE INSERT ON tasks
+-- WHEN NEW.project_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM projects WHERE id = NEW.project_id)
+-- BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+-- CREATE TRIGGER IF NOT EXISTS tasks_project_id_update_fkey BEFORE UPDATE OF project_id ON tasks
+-- WHEN NEW.project_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM projects WHERE id = NEW.project_id)
+-- BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+-- CREATE TRIGGER IF NOT EXISTS projects_id_delete_fkey BEFORE DELETE ON projects
+-- WHEN EXISTS (SELECT 1 FROM tasks WHERE project_id = OLD.id)
+-- BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+-- +sqlite end
+
+-- +migrate Down
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_project_id_fkey;
@@ -0,0 +1,61 @@
+package apikeyhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+type apiKeyHandler struct {
+	app           *fiber.App
+	apiKeyService ports.APIKeyService
+}
+
+func NewAPIKeyHandler(app *fiber.App, apiKeyService ports.APIKeyService) {
+	apiKeyHandler := apiKeyHandler{
+		app:           app,
+		apiKeyService: apiKeyService,
+	}
+
+	api := apiKeyHandler.app.Group("/apikey")
+	api.Post("/add", apiKeyHandler.create)
+	api.Get("/get", apiKeyHandler.getAll)
+	api.Delete("/delete/:id", apiKeyHandler.delete)
+}
+
+func (instance *apiKeyHandler) create(c *fiber.Ctx) error {
+	request := new(domain.CreateAPIKeyRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	apiKey, err := instance.apiKeyService.Create(c.Context(), request)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(apiKey))
+}
+
+func (instance *apiKeyHandler) getAll(c *fiber.Ctx) error {
+	apiKeys, err := instance.apiKeyService.GetAll(c.Context())
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(apiKeys))
+}
+
+func (instance *apiKeyHandler) delete(c *fiber.Ctx) error {
+	if err := instance.apiKeyService.Delete(c.Context(), c.Params("id")); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
@@ -0,0 +1,80 @@
+package authhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+type authHandler struct {
+	app         *fiber.App
+	authService ports.AuthService
+}
+
+func NewAuthHandler(app *fiber.App, authService ports.AuthService) {
+	authHandler := authHandler{
+		app:         app,
+		authService: authService,
+	}
+
+	api := authHandler.app.Group("/auth")
+	api.Post("/register", authHandler.register)
+	api.Post("/login", authHandler.login)
+	api.Post("/refresh", authHandler.refresh)
+}
+
+func (instance *authHandler) register(c *fiber.Ctx) error {
+	request := new(domain.RegisterRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	user, err := instance.authService.Register(c.Context(), request)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(user))
+}
+
+func (instance *authHandler) login(c *fiber.Ctx) error {
+	request := new(domain.LoginRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	tokens, err := instance.authService.Login(c.Context(), request)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(tokens))
+}
+
+func (instance *authHandler) refresh(c *fiber.Ctx) error {
+	request := new(domain.RefreshRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	tokens, err := instance.authService.Refresh(c.Context(), request)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(tokens))
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+)
+
+// Actor marks the changes made by a request as anonymous, the services read the actor from the
+// request context
+func Actor() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(domain.ActorKey, domain.ActorAnonymous)
+
+		return c.Next()
+	}
+}
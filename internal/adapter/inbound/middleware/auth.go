@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"strconv"
+	"strings"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+var (
+	MissingToken       = "Authorization header must be a Bearer token"
+	ReadOnlyAPIKey     = "API key is read-only"
+	APIKeyNotAllowed   = "API keys can't be used here, login instead"
+	MissingCredentials = "Authorization header must be a Bearer token or API key, or X-API-Key must be set"
+)
+
+// Auth refuses the requests without a valid access token, the services read the user the
+// request is made by from the request context and only see its rows
+func Auth(authService ports.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := bearerToken(c.Get(fiber.HeaderAuthorization))
+		if !ok {
+			return responseErr.Response(c, responseErr.ResponseUnauthorized(MissingToken))
+		}
+		if domain.IsAPIKey(token) {
+			return responseErr.Response(c, responseErr.ResponseUnauthorized(APIKeyNotAllowed))
+		}
+
+		return authenticateToken(c, authService, token)
+	}
+}
+
+// AuthWithAPIKey is Auth also accepting an API key, as a Bearer token or in the X-API-Key header.
+// A read-only key may only make GET requests.
+func AuthWithAPIKey(authService ports.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderAPIKey)
+		token, ok := bearerToken(c.Get(fiber.HeaderAuthorization))
+		if key == "" && ok && domain.IsAPIKey(token) {
+			key = token
+		}
+
+		if key == "" {
+			if !ok {
+				return responseErr.Response(c, responseErr.ResponseUnauthorized(MissingCredentials))
+			}
+
+			return authenticateToken(c, authService, token)
+		}
+
+		apiKey, err := authService.AuthenticateKey(c.Context(), key)
+		if err != nil {
+			return responseErr.Response(c, err)
+		}
+
+		if !apiKey.Allows(c.Method()) {
+			return responseErr.Response(c, responseErr.ResponseForbidden(ReadOnlyAPIKey))
+		}
+
+		c.Locals(domain.OwnerKey, apiKey.OwnerID)
+		c.Locals(domain.ActorKey, "apikey:"+strconv.FormatUint(apiKey.ID, 10))
+
+		return c.Next()
+	}
+}
+
+func authenticateToken(c *fiber.Ctx, authService ports.AuthService, token string) error {
+	user, err := authService.Authenticate(c.Context(), token)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	c.Locals(domain.OwnerKey, user.ID)
+	c.Locals(domain.ActorKey, "user:"+strconv.FormatUint(user.ID, 10))
+
+	return c.Next()
+}
+
+func bearerToken(header string) (string, bool) {
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(parts[1]), true
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/apikeyrps"
+	"github.com/todo-list/internal/adapter/outbound/userrps"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"github.com/todo-list/internal/core/services/authsvc"
+	"go.uber.org/zap"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestApp serves the owner of the request on /task and /apikey, behind the same middlewares
+// as the server
+func newTestApp(t *testing.T) (*fiber.App, ports.APIKeyRepository) {
+	t.Helper()
+
+	apiKeyRepo := apikeyrps.NewAPIKeyMemory()
+	authService := authsvc.NewAuthService(zap.NewNop(), userrps.NewUserMemory(), apiKeyRepo, authsvc.Tokens{
+		Secret:    []byte("0123456789abcdef0123456789abcdef"),
+		Issuer:    "todo-list",
+		AccessTTL: time.Minute,
+	})
+
+	owner := func(c *fiber.Ctx) error {
+		owner, _ := c.Locals(domain.OwnerKey).(uint64)
+		return c.SendString(strconv.FormatUint(owner, 10))
+	}
+
+	app := fiber.New()
+	app.Get("/task", AuthWithAPIKey(authService), owner)
+	app.Post("/task", AuthWithAPIKey(authService), owner)
+	app.Get("/apikey", Auth(authService), owner)
+
+	return app, apiKeyRepo
+}
+
+// createTestKey stores a key of owner 7 and returns the plain key
+func createTestKey(t *testing.T, apiKeyRepo ports.APIKeyRepository, scope string, expiresAt *time.Time) string {
+	t.Helper()
+
+	apiKey, key, err := (&domain.CreateAPIKeyRequest{Name: "script", Scope: scope}).ToBase(7)
+	require.NoError(t, err)
+	apiKey.ExpiresAt = expiresAt
+	require.NoError(t, apiKeyRepo.Create(domain.WithOwner(context.Background(), 7), apiKey))
+
+	return key
+}
+
+func request(t *testing.T, app *fiber.App, method string, path string, header string, value string) (int, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestAPIKeyScope(t *testing.T) {
+	app, apiKeyRepo := newTestApp(t)
+	read := createTestKey(t, apiKeyRepo, domain.ScopeRead, nil)
+	readWrite := createTestKey(t, apiKeyRepo, domain.ScopeReadWrite, nil)
+
+	status, body := request(t, app, "GET", "/task", fiber.HeaderAuthorization, "Bearer "+read)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "7", body)
+
+	status, _ = request(t, app, "GET", "/task", HeaderAPIKey, read)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	// a read key may not write, a read-write key may
+	status, _ = request(t, app, "POST", "/task", HeaderAPIKey, read)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	status, _ = request(t, app, "POST", "/task", HeaderAPIKey, readWrite)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	// the keys are managed with an access token only
+	status, _ = request(t, app, "GET", "/apikey", fiber.HeaderAuthorization, "Bearer "+readWrite)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	app, apiKeyRepo := newTestApp(t)
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	status, _ := request(t, app, "GET", "/task", HeaderAPIKey, createTestKey(t, apiKeyRepo, domain.ScopeRead, &past))
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	status, _ = request(t, app, "GET", "/task", HeaderAPIKey, createTestKey(t, apiKeyRepo, domain.ScopeRead, &future))
+	assert.Equal(t, fiber.StatusOK, status)
+
+	status, _ = request(t, app, "GET", "/task", HeaderAPIKey, domain.APIKeyPrefix+"unknown")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	status, _ = request(t, app, "GET", "/task", "", "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+}
@@ -0,0 +1,89 @@
+package projecthdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+type projectHandler struct {
+	app            *fiber.App
+	projectService ports.ProjectService
+}
+
+func NewProjectHandler(app *fiber.App, projectService ports.ProjectService) {
+	projectHandler := projectHandler{
+		app:            app,
+		projectService: projectService,
+	}
+
+	api := projectHandler.app.Group("/project")
+	api.Post("/add", projectHandler.create)
+	api.Get("/get/:id", projectHandler.getOneById)
+	api.Put("/update/:id", projectHandler.update)
+	api.Delete("/delete/:id", projectHandler.delete)
+	api.Get("/get", projectHandler.getAll)
+}
+
+func (instance *projectHandler) create(c *fiber.Ctx) error {
+	request := new(domain.CreateProjectRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	project, err := instance.projectService.Create(c.Context(), request)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(project))
+}
+
+func (instance *projectHandler) getOneById(c *fiber.Ctx) error {
+	project, err := instance.projectService.GetOneByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(project))
+}
+
+func (instance *projectHandler) update(c *fiber.Ctx) error {
+	request := new(domain.UpdateProjectRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := instance.projectService.Update(c.Context(), c.Params("id"), request); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *projectHandler) delete(c *fiber.Ctx) error {
+	if err := instance.projectService.Delete(c.Context(), c.Params("id")); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *projectHandler) getAll(c *fiber.Ctx) error {
+	projects, err := instance.projectService.GetAll(c.Context())
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(projects))
+}
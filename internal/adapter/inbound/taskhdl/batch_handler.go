@@ -0,0 +1,27 @@
+package taskhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+// batch answers with the result of every operation, also when an all or nothing batch was rolled back
+func (instance *taskHandler) batch(c *fiber.Ctx) error {
+	request := new(domain.BatchRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	batch, err := instance.taskService.Batch(c.Context(), request)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(batch))
+}
@@ -0,0 +1,23 @@
+package taskhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+func (instance *taskHandler) addBlocker(c *fiber.Ctx) error {
+	if err := instance.taskService.AddBlocker(c.Context(), c.Params("id"), c.Params("blocker_id")); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *taskHandler) removeBlocker(c *fiber.Ctx) error {
+	if err := instance.taskService.RemoveBlocker(c.Context(), c.Params("id"), c.Params("blocker_id")); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
@@ -0,0 +1,80 @@
+package taskhdl
+
+import (
+	"bufio"
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+	"path/filepath"
+	"strings"
+)
+
+const FormFile = "File"
+
+var MissingFile = "File must be uploaded as multipart/form-data"
+
+// export streams the tasks matching the filters of TaskParams, Page, Limit, Cursor and Sort aren't
+// used since every task is exported
+func (instance *taskHandler) export(c *fiber.Ctx) error {
+	format := c.Query("format", domain.FormatJSON)
+	if err := domain.ValidFormat(format); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	params := new(domain.TaskParams)
+	if err := c.QueryParser(params); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	// the page rules don't apply to an export
+	params.Page, params.Limit, params.Cursor, params.Sort, params.Order = 1, 1, nil, nil, nil
+	if err := params.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	// the stream is written after the handler returned, when the request context can't be used anymore
+	owner, _ := domain.OwnerFromContext(c.Context())
+	ctx := domain.WithOwner(context.Background(), owner)
+
+	contentType := fiber.MIMEApplicationJSONCharsetUTF8
+	if format == domain.FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="tasks.`+format+`"`)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		_ = instance.taskService.Export(ctx, params, format, w)
+	})
+
+	return nil
+}
+
+// importTasks reads the file uploaded in the File form field, its format is the format query or else
+// the extension of the file
+func (instance *taskHandler) importTasks(c *fiber.Ctx) error {
+	header, err := c.FormFile(FormFile)
+	if err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(MissingFile))
+	}
+
+	format := c.Query("format", strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), ".")))
+	if err := domain.ValidFormat(format); err != nil || format == "" {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(domain.ErrInvalidFormat.Error()))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+	defer file.Close()
+
+	result, err := instance.taskService.Import(c.Context(), format, file)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(result))
+}
@@ -0,0 +1,26 @@
+package taskhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+func (instance *taskHandler) getHistory(c *fiber.Ctx) error {
+	params := new(domain.AuditLogParams)
+	if err := c.QueryParser(params); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := params.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	history, err := instance.taskService.GetHistory(c.Context(), c.Params("id"), params)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(history))
+}
@@ -0,0 +1,58 @@
+package taskhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+func (instance *taskHandler) addObjective(c *fiber.Ctx) error {
+	request := new(domain.CreateObjectiveRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := instance.taskService.AddObjective(c.Context(), c.Params("id"), request, c.Get(fiber.HeaderIfMatch)); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *taskHandler) renameObjective(c *fiber.Ctx) error {
+	request := new(domain.RenameObjectiveRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := instance.taskService.RenameObjective(c.Context(), c.Params("id"), c.Params("objective_id"), request, c.Get(fiber.HeaderIfMatch)); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *taskHandler) toggleObjective(c *fiber.Ctx) error {
+	if err := instance.taskService.ToggleObjective(c.Context(), c.Params("id"), c.Params("objective_id"), c.Get(fiber.HeaderIfMatch)); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *taskHandler) deleteObjective(c *fiber.Ctx) error {
+	if err := instance.taskService.DeleteObjective(c.Context(), c.Params("id"), c.Params("objective_id"), c.Get(fiber.HeaderIfMatch)); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
@@ -0,0 +1,30 @@
+package taskhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+func (instance *taskHandler) getSubtree(c *fiber.Ctx) error {
+	tree, err := instance.taskService.GetSubtree(c.Context(), c.Params("id"))
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(tree))
+}
+
+func (instance *taskHandler) move(c *fiber.Ctx) error {
+	request := new(domain.MoveTaskRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := instance.taskService.Move(c.Context(), c.Params("id"), request, c.Get(fiber.HeaderIfMatch)); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
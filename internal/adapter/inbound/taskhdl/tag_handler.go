@@ -0,0 +1,16 @@
+package taskhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+func (instance *taskHandler) getTags(c *fiber.Ctx) error {
+	tags, err := instance.taskService.GetTags(c.Context())
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(tags))
+}
@@ -2,12 +2,16 @@ package taskhdl
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/todo-list/internal/core/domain"
 	"github.com/todo-list/internal/core/ports"
 	responseErr "github.com/todo-list/internal/error"
 	"github.com/todo-list/internal/response"
+	"strings"
 )
 
+const MIMEMergePatch = "application/merge-patch+json"
+
 type taskHandler struct {
 	app         *fiber.App
 	taskService ports.TaskService
@@ -21,10 +25,27 @@ func NewTaskHandler(app *fiber.App, taskService ports.TaskService) {
 
 	api := taskHandler.app.Group("/task")
 	api.Post("/add", taskHandler.create)
+	api.Post("/batch", taskHandler.batch)
 	api.Get("/get/:id", taskHandler.getOneById)
 	api.Put("/update/:id", taskHandler.update)
+	api.Patch("/:id", taskHandler.patch)
 	api.Delete("/delete/:id", taskHandler.delete)
 	api.Get("/get", taskHandler.getAllWithPaginate)
+	api.Post("/:id/objectives", taskHandler.addObjective)
+	api.Put("/:id/objectives/:objective_id", taskHandler.renameObjective)
+	api.Put("/:id/objectives/:objective_id/toggle", taskHandler.toggleObjective)
+	api.Delete("/:id/objectives/:objective_id", taskHandler.deleteObjective)
+	api.Get("/trash", taskHandler.getTrash)
+	api.Post("/:id/restore", taskHandler.restore)
+	api.Delete("/:id/purge", taskHandler.purge)
+	api.Get("/:id/history", taskHandler.getHistory)
+	api.Get("/tags", taskHandler.getTags)
+	api.Get("/export", taskHandler.export)
+	api.Post("/import", taskHandler.importTasks)
+	api.Get("/:id/subtree", taskHandler.getSubtree)
+	api.Put("/:id/move", taskHandler.move)
+	api.Post("/:id/blockers/:blocker_id", taskHandler.addBlocker)
+	api.Delete("/:id/blockers/:blocker_id", taskHandler.removeBlocker)
 }
 
 func (instance *taskHandler) create(c *fiber.Ctx) error {
@@ -33,7 +54,9 @@ func (instance *taskHandler) create(c *fiber.Ctx) error {
 		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
 	}
 
-	// validate here
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
 
 	if err := instance.taskService.Create(c.Context(), request); err != nil {
 		return responseErr.Response(c, err)
@@ -48,6 +71,8 @@ func (instance *taskHandler) getOneById(c *fiber.Ctx) error {
 		return responseErr.Response(c, err)
 	}
 
+	c.Set(fiber.HeaderETag, task.ETag())
+
 	return response.Success(c, fiber.StatusOK, response.SuccessData(task))
 }
 
@@ -57,9 +82,35 @@ func (instance *taskHandler) update(c *fiber.Ctx) error {
 		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
 	}
 
-	// validate here ...
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	scope := c.Query("Scope")
+	if err := domain.ValidScope(scope); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := instance.taskService.Update(c.Context(), c.Params("id"), request, c.Get(fiber.HeaderIfMatch), scope); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+// patch accepts an RFC 7396 JSON merge patch
+func (instance *taskHandler) patch(c *fiber.Ctx) error {
+	contentType := utils.ToLower(utils.UnsafeString(c.Request().Header.ContentType()))
+	if !strings.HasPrefix(contentType, MIMEMergePatch) && !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		return responseErr.Response(c, responseErr.ResponseBadRequest("Content-Type must be "+MIMEMergePatch))
+	}
+
+	scope := c.Query("Scope")
+	if err := domain.ValidScope(scope); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
 
-	if err := instance.taskService.Update(c.Context(), c.Params("id"), request); err != nil {
+	if err := instance.taskService.Patch(c.Context(), c.Params("id"), c.Body(), c.Get(fiber.HeaderIfMatch), scope); err != nil {
 		return responseErr.Response(c, err)
 	}
 
@@ -67,7 +118,7 @@ func (instance *taskHandler) update(c *fiber.Ctx) error {
 }
 
 func (instance *taskHandler) delete(c *fiber.Ctx) error {
-	if err := instance.taskService.Delete(c.Context(), c.Params("id")); err != nil {
+	if err := instance.taskService.Delete(c.Context(), c.Params("id"), c.Get(fiber.HeaderIfMatch)); err != nil {
 		return responseErr.Response(c, err)
 	}
 
@@ -0,0 +1,42 @@
+package taskhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+func (instance *taskHandler) getTrash(c *fiber.Ctx) error {
+	params := new(domain.TaskParams)
+	if err := c.QueryParser(params); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := params.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	tasks, err := instance.taskService.GetTrash(c.Context(), params)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(tasks))
+}
+
+func (instance *taskHandler) restore(c *fiber.Ctx) error {
+	if err := instance.taskService.Restore(c.Context(), c.Params("id"), c.Get(fiber.HeaderIfMatch)); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *taskHandler) purge(c *fiber.Ctx) error {
+	if err := instance.taskService.Purge(c.Context(), c.Params("id"), c.Get(fiber.HeaderIfMatch)); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
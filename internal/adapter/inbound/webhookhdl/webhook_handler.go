@@ -0,0 +1,108 @@
+package webhookhdl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/internal/response"
+)
+
+type webhookHandler struct {
+	app            *fiber.App
+	webhookService ports.WebhookService
+}
+
+func NewWebhookHandler(app *fiber.App, webhookService ports.WebhookService) {
+	webhookHandler := webhookHandler{
+		app:            app,
+		webhookService: webhookService,
+	}
+
+	api := webhookHandler.app.Group("/webhook")
+	api.Post("/add", webhookHandler.create)
+	api.Get("/get/:id", webhookHandler.getOneById)
+	api.Put("/update/:id", webhookHandler.update)
+	api.Delete("/delete/:id", webhookHandler.delete)
+	api.Get("/get", webhookHandler.getAll)
+	api.Get("/:id/deliveries", webhookHandler.getDeliveries)
+}
+
+func (instance *webhookHandler) create(c *fiber.Ctx) error {
+	request := new(domain.CreateWebhookRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	webhook, err := instance.webhookService.Create(c.Context(), request)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(webhook))
+}
+
+func (instance *webhookHandler) getOneById(c *fiber.Ctx) error {
+	webhook, err := instance.webhookService.GetOneByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(webhook))
+}
+
+func (instance *webhookHandler) update(c *fiber.Ctx) error {
+	request := new(domain.UpdateWebhookRequest)
+	if err := c.BodyParser(&request); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := instance.webhookService.Update(c.Context(), c.Params("id"), request); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *webhookHandler) delete(c *fiber.Ctx) error {
+	if err := instance.webhookService.Delete(c.Context(), c.Params("id")); err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessMessage("Success"))
+}
+
+func (instance *webhookHandler) getAll(c *fiber.Ctx) error {
+	webhooks, err := instance.webhookService.GetAll(c.Context())
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(webhooks))
+}
+
+func (instance *webhookHandler) getDeliveries(c *fiber.Ctx) error {
+	params := new(domain.WebhookDeliveryParams)
+	if err := c.QueryParser(params); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	if err := params.Validate(); err != nil {
+		return responseErr.Response(c, responseErr.ResponseBadRequest(err.Error()))
+	}
+
+	deliveries, err := instance.webhookService.GetDeliveries(c.Context(), c.Params("id"), params)
+	if err != nil {
+		return responseErr.Response(c, err)
+	}
+
+	return response.Success(c, fiber.StatusOK, response.SuccessData(deliveries))
+}
@@ -0,0 +1,119 @@
+package apikeyrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type apiKeyMemory struct {
+	mu        sync.RWMutex
+	apiKeySeq uint64
+	apiKeys   map[uint64]*domain.APIKey
+}
+
+func NewAPIKeyMemory() ports.APIKeyRepository {
+	return &apiKeyMemory{
+		apiKeys: map[uint64]*domain.APIKey{},
+	}
+}
+
+func (instance *apiKeyMemory) Create(ctx context.Context, apiKey *domain.APIKey) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	instance.apiKeySeq++
+	apiKey.ID = instance.apiKeySeq
+	apiKey.CreatedAt = time.Now()
+
+	instance.apiKeys[apiKey.ID] = copyAPIKey(apiKey)
+
+	return nil
+}
+
+func (instance *apiKeyMemory) Delete(ctx context.Context, apiKey *domain.APIKey) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	delete(instance.apiKeys, apiKey.ID)
+
+	return nil
+}
+
+func (instance *apiKeyMemory) Touch(ctx context.Context, apiKey *domain.APIKey) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	if stored, ok := instance.apiKeys[apiKey.ID]; ok {
+		stored.LastUsedAt = copyAPIKey(apiKey).LastUsedAt
+	}
+
+	return nil
+}
+
+func (instance *apiKeyMemory) GetOneByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	apiKeyID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	apiKey, ok := instance.apiKeys[apiKeyID]
+	if !ok || !domain.OwnedBy(ctx, apiKey.OwnerID) {
+		return nil, nil
+	}
+
+	return copyAPIKey(apiKey), nil
+}
+
+func (instance *apiKeyMemory) GetOneByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	for _, apiKey := range instance.apiKeys {
+		if apiKey.KeyHash == hash {
+			return copyAPIKey(apiKey), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (instance *apiKeyMemory) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var apiKeys []*domain.APIKey
+	for _, apiKey := range instance.apiKeys {
+		if domain.OwnedBy(ctx, apiKey.OwnerID) {
+			apiKeys = append(apiKeys, copyAPIKey(apiKey))
+		}
+	}
+
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].ID < apiKeys[j].ID
+	})
+
+	return apiKeys, nil
+}
+
+// copyAPIKey returns a deep copy of apiKey so stored keys can't be mutated by callers
+func copyAPIKey(apiKey *domain.APIKey) *domain.APIKey {
+	copied := *apiKey
+	if apiKey.ExpiresAt != nil {
+		expiresAt := *apiKey.ExpiresAt
+		copied.ExpiresAt = &expiresAt
+	}
+	if apiKey.LastUsedAt != nil {
+		lastUsedAt := *apiKey.LastUsedAt
+		copied.LastUsedAt = &lastUsedAt
+	}
+
+	return &copied
+}
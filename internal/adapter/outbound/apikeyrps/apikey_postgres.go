@@ -0,0 +1,71 @@
+package apikeyrps
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/adapter/outbound/scope"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"gorm.io/gorm"
+	"time"
+)
+
+type apiKeyPostgres struct {
+	postgres *gorm.DB
+}
+
+func NewAPIKeyPostgres(postgres *gorm.DB) ports.APIKeyRepository {
+	return &apiKeyPostgres{
+		postgres: postgres,
+	}
+}
+
+func (instance *apiKeyPostgres) Create(ctx context.Context, apiKey *domain.APIKey) error {
+	apiKey.CreatedAt = time.Now()
+
+	return instance.postgres.Debug().Create(apiKey).Error
+}
+
+func (instance *apiKeyPostgres) Delete(ctx context.Context, apiKey *domain.APIKey) error {
+	return instance.postgres.Debug().Where("id = ?", apiKey.ID).Delete(&domain.APIKey{}).Error
+}
+
+// Touch saves the last used time of the key
+func (instance *apiKeyPostgres) Touch(ctx context.Context, apiKey *domain.APIKey) error {
+	return instance.postgres.Debug().Model(&domain.APIKey{}).
+		Where("id = ?", apiKey.ID).
+		Update("last_used_at", apiKey.LastUsedAt).Error
+}
+
+func (instance *apiKeyPostgres) GetOneByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	return instance.getOne(instance.postgres.Scopes(scope.Owner(ctx)).Where("id = ?", id))
+}
+
+// GetOneByHash isn't scoped, it is used to find the owner of the key
+func (instance *apiKeyPostgres) GetOneByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return instance.getOne(instance.postgres.Where("key_hash = ?", hash))
+}
+
+func (instance *apiKeyPostgres) getOne(q *gorm.DB) (*domain.APIKey, error) {
+	var apiKey *domain.APIKey
+
+	if err := q.Debug().First(&apiKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (instance *apiKeyPostgres) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	var apiKeys []*domain.APIKey
+
+	if err := instance.postgres.Debug().Scopes(scope.Owner(ctx)).Order("id").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
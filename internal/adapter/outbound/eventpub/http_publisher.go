@@ -0,0 +1,58 @@
+package eventpub
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEventID = "X-Event-ID"
+	HeaderEvent   = "X-Event"
+)
+
+type httpPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher posts the payload of every event to url, a response other than 2xx fails
+// the publication so it is retried
+func NewHTTPPublisher(url string, timeout time.Duration) ports.EventPublisher {
+	return &httpPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (instance *httpPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, instance.url, bytes.NewReader([]byte(event.Payload)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(HeaderEventID, strconv.FormatUint(event.ID, 10))
+	req.Header.Set(HeaderEvent, event.Type)
+
+	resp, err := instance.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event publisher responded %d", resp.StatusCode)
+	}
+
+	return nil
+}
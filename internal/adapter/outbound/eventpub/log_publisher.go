@@ -0,0 +1,29 @@
+package eventpub
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+type logPublisher struct {
+	log *zap.Logger
+}
+
+// NewLogPublisher writes events to the log, it is the publisher used until a broker is configured
+func NewLogPublisher(log *zap.Logger) ports.EventPublisher {
+	return &logPublisher{
+		log: log,
+	}
+}
+
+func (instance *logPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	instance.log.Info("task event",
+		zap.Uint64("event_id", event.ID),
+		zap.String("event", event.Type),
+		zap.Uint64("task_id", event.TaskID),
+		zap.String("payload", event.Payload))
+
+	return nil
+}
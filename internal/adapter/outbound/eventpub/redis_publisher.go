@@ -0,0 +1,38 @@
+package eventpub
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+)
+
+type redisPublisher struct {
+	redis  *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisPublisher appends events to a redis stream trimmed to about maxLen entries, a zero
+// maxLen never trims it
+func NewRedisPublisher(redis *redis.Client, stream string, maxLen int64) ports.EventPublisher {
+	return &redisPublisher{
+		redis:  redis,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (instance *redisPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	return instance.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: instance.stream,
+		MaxLen: instance.maxLen,
+		Approx: instance.maxLen > 0,
+		Values: map[string]interface{}{
+			"event_id": event.ID,
+			"event":    event.Type,
+			"task_id":  event.TaskID,
+			"payload":  event.Payload,
+		},
+	}).Err()
+}
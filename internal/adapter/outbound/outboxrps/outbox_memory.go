@@ -0,0 +1,93 @@
+package outboxrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"sort"
+	"sync"
+	"time"
+)
+
+type outboxMemory struct {
+	mu       sync.RWMutex
+	eventSeq uint64
+	events   map[uint64]*domain.OutboxEvent
+}
+
+// NewOutboxMemory is shared with the memory task repository, which writes its events with CreateEvents
+func NewOutboxMemory() ports.OutboxRepository {
+	return &outboxMemory{
+		events: map[uint64]*domain.OutboxEvent{},
+	}
+}
+
+func (instance *outboxMemory) CreateEvents(ctx context.Context, events []*domain.OutboxEvent) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	for _, event := range events {
+		instance.eventSeq++
+		event.ID = instance.eventSeq
+
+		copied := *event
+		instance.events[event.ID] = &copied
+	}
+
+	return nil
+}
+
+// ClaimEvent is locking an unpublished event until so no other relay publishes it meanwhile, it fails
+// with domain.ErrEventClaimed when the event was published, dead lettered or locked by another relay
+func (instance *outboxMemory) ClaimEvent(ctx context.Context, event *domain.OutboxEvent, now time.Time, until time.Time) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.events[event.ID]
+	if !ok || stored.PublishedAt != nil || stored.DeadLetteredAt != nil || stored.LockedUntil.After(now) {
+		return domain.ErrEventClaimed
+	}
+
+	stored.LockedUntil = until
+	event.LockedUntil = until
+
+	return nil
+}
+
+// UpdateEvent is saving the outcome of a publication
+func (instance *outboxMemory) UpdateEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	if _, ok := instance.events[event.ID]; ok {
+		copied := *event
+		instance.events[event.ID] = &copied
+	}
+
+	return nil
+}
+
+// GetAllUnpublished is getting the events neither published nor dead lettered in the order they were
+// written, the locked ones included so the relay holds back the events following them
+func (instance *outboxMemory) GetAllUnpublished(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEvent, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var events []*domain.OutboxEvent
+	for _, event := range instance.events {
+		if event.PublishedAt == nil && event.DeadLetteredAt == nil {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
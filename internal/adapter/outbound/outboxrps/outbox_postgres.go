@@ -0,0 +1,76 @@
+package outboxrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"gorm.io/gorm"
+	"time"
+)
+
+type outboxPostgres struct {
+	postgres *gorm.DB
+}
+
+func NewOutboxPostgres(postgres *gorm.DB) ports.OutboxRepository {
+	return &outboxPostgres{
+		postgres: postgres,
+	}
+}
+
+// CreateEvents is only used outside of a task transaction, the task repositories write their
+// events in the transaction saving the task
+func (instance *outboxPostgres) CreateEvents(ctx context.Context, events []*domain.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return instance.postgres.Debug().Create(&events).Error
+}
+
+// ClaimEvent is locking an unpublished event until so no other relay publishes it meanwhile, it fails
+// with domain.ErrEventClaimed when the event was published, dead lettered or locked by another relay
+func (instance *outboxPostgres) ClaimEvent(ctx context.Context, event *domain.OutboxEvent, now time.Time, until time.Time) error {
+	result := instance.postgres.Debug().Model(&domain.OutboxEvent{}).
+		Where("id = ? AND published_at IS NULL AND dead_lettered_at IS NULL AND locked_until <= ?", event.ID, now.UTC()).
+		Update("locked_until", until.UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrEventClaimed
+	}
+
+	event.LockedUntil = until
+
+	return nil
+}
+
+// UpdateEvent is saving the outcome of a publication
+func (instance *outboxPostgres) UpdateEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	return instance.postgres.Debug().Model(&domain.OutboxEvent{}).
+		Where("id = ?", event.ID).
+		Updates(map[string]interface{}{
+			"attempts":         event.Attempts,
+			"error":            event.Error,
+			"locked_until":     event.LockedUntil.UTC(),
+			"published_at":     event.PublishedAt,
+			"dead_lettered_at": event.DeadLetteredAt,
+		}).Error
+}
+
+// GetAllUnpublished is getting the events neither published nor dead lettered in the order they were
+// written, the locked ones included so the relay holds back the events following them
+func (instance *outboxPostgres) GetAllUnpublished(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEvent, error) {
+	var events []*domain.OutboxEvent
+
+	if err := instance.postgres.Debug().
+		Where("published_at IS NULL AND dead_lettered_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,102 @@
+package projectrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type projectMemory struct {
+	mu         sync.RWMutex
+	projectSeq uint64
+	projects   map[uint64]*domain.Project
+}
+
+func NewProjectMemory() ports.ProjectRepository {
+	return &projectMemory{
+		projects: map[uint64]*domain.Project{},
+	}
+}
+
+func (instance *projectMemory) Create(ctx context.Context, project *domain.Project) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	now := time.Now()
+
+	instance.projectSeq++
+	project.ID = instance.projectSeq
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	copied := *project
+	instance.projects[project.ID] = &copied
+
+	return nil
+}
+
+func (instance *projectMemory) Update(ctx context.Context, project *domain.Project) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.projects[project.ID]
+	if !ok {
+		return nil
+	}
+
+	project.UpdatedAt = time.Now()
+	stored.Name = project.Name
+	stored.UpdatedAt = project.UpdatedAt
+
+	return nil
+}
+
+func (instance *projectMemory) Delete(ctx context.Context, project *domain.Project) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	delete(instance.projects, project.ID)
+
+	return nil
+}
+
+func (instance *projectMemory) GetOneByID(ctx context.Context, id string) (*domain.Project, error) {
+	projectID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	project, ok := instance.projects[projectID]
+	if !ok || !domain.OwnedBy(ctx, project.OwnerID) {
+		return nil, nil
+	}
+
+	copied := *project
+	return &copied, nil
+}
+
+func (instance *projectMemory) GetAll(ctx context.Context) ([]*domain.Project, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var projects []*domain.Project
+	for _, project := range instance.projects {
+		if domain.OwnedBy(ctx, project.OwnerID) {
+			copied := *project
+			projects = append(projects, &copied)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, nil
+}
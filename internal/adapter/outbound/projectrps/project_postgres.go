@@ -0,0 +1,94 @@
+package projectrps
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"github.com/todo-list/internal/adapter/outbound/scope"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// foreignKeyViolation is the SQLSTATE of postgres for a violated foreign key
+const foreignKeyViolation = "23503"
+
+type projectPostgres struct {
+	postgres *gorm.DB
+}
+
+func NewProjectPostgres(postgres *gorm.DB) ports.ProjectRepository {
+	return &projectPostgres{
+		postgres: postgres,
+	}
+}
+
+func (instance *projectPostgres) Create(ctx context.Context, project *domain.Project) error {
+	now := time.Now()
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	return instance.postgres.Debug().Create(project).Error
+}
+
+func (instance *projectPostgres) Update(ctx context.Context, project *domain.Project) error {
+	project.UpdatedAt = time.Now()
+
+	return instance.postgres.Debug().Model(&domain.Project{}).
+		Where("id = ?", project.ID).
+		Updates(map[string]interface{}{
+			"name":       project.Name,
+			"updated_at": project.UpdatedAt,
+		}).Error
+}
+
+// Delete returns domain.ErrProjectNotEmpty when the foreign key of a task still refers to the project
+func (instance *projectPostgres) Delete(ctx context.Context, project *domain.Project) error {
+	err := instance.postgres.Debug().Where("id = ?", project.ID).Delete(&domain.Project{}).Error
+	if isForeignKeyViolation(err) {
+		return domain.ErrProjectNotEmpty
+	}
+
+	return err
+}
+
+// isForeignKeyViolation recognizes the foreign key errors of postgres and of sqlite, which only
+// reports it by its message
+func isForeignKeyViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == foreignKeyViolation
+	}
+
+	return strings.Contains(err.Error(), "FOREIGN KEY constraint failed")
+}
+
+func (instance *projectPostgres) GetOneByID(ctx context.Context, id string) (*domain.Project, error) {
+	var project *domain.Project
+
+	if err := instance.postgres.Debug().Scopes(scope.Owner(ctx)).Where("id = ?", id).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (instance *projectPostgres) GetAll(ctx context.Context) ([]*domain.Project, error) {
+	var projects []*domain.Project
+
+	if err := instance.postgres.Debug().Scopes(scope.Owner(ctx)).Order("id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
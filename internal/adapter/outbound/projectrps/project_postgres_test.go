@@ -0,0 +1,42 @@
+package projectrps
+
+import (
+	"context"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/taskrps"
+	"github.com/todo-list/internal/core/domain"
+	sqlitePkg "github.com/todo-list/pkg/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeleteRefusedByTaskForeignKey(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, sqlitePkg.Migrate(db, filepath.Join("..", "..", "..", "..", "cmd", "migration")))
+
+	ctx := context.Background()
+	projectRepo := NewProjectPostgres(db)
+	taskRepo := taskrps.NewTaskSQLite(db)
+
+	project := &domain.Project{OwnerID: 1, Name: "home"}
+	require.NoError(t, projectRepo.Create(ctx, project))
+
+	// a task created in the project after the service counted its tasks still keeps it
+	task := &domain.Task{OwnerID: 1, Title: "paint", ActionTime: time.Now(), Version: 1, ProjectID: &project.ID}
+	require.NoError(t, taskRepo.Create(ctx, task))
+
+	assert.ErrorIs(t, projectRepo.Delete(ctx, project), domain.ErrProjectNotEmpty)
+
+	// a task can't be moved to a deleted project either
+	empty := &domain.Project{OwnerID: 1, Name: "garden"}
+	require.NoError(t, projectRepo.Create(ctx, empty))
+	require.NoError(t, projectRepo.Delete(ctx, empty))
+
+	orphan := &domain.Task{OwnerID: 1, Title: "mow", ActionTime: time.Now(), Version: 1, ProjectID: &empty.ID}
+	assert.Error(t, taskRepo.Create(ctx, orphan))
+}
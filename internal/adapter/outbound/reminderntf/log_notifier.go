@@ -0,0 +1,29 @@
+package reminderntf
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+type logNotifier struct {
+	log *zap.Logger
+}
+
+// NewLogNotifier writes reminders to the log, it is the notifier used until a delivery channel is configured
+func NewLogNotifier(log *zap.Logger) ports.ReminderNotifier {
+	return &logNotifier{
+		log: log,
+	}
+}
+
+func (instance *logNotifier) Notify(ctx context.Context, reminder *domain.Reminder) error {
+	instance.log.Info("task reminder",
+		zap.Uint64("task_id", reminder.TaskID),
+		zap.String("title", reminder.Task.Title),
+		zap.Time("action_time", reminder.ActionTime),
+		zap.Duration("offset", reminder.Offset()))
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package scope
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"gorm.io/gorm"
+)
+
+// Owner limits a query to the rows of the user set on ctx
+func Owner(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return OwnerColumn(ctx, "owner_id")
+}
+
+// OwnerColumn limits a query to the rows whose column is the user set on ctx. The workers have no
+// user, they are made by domain.WithSystem and see the rows of every user. Any other query without a
+// user sees no row.
+func OwnerColumn(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		if owner, ok := domain.OwnerFromContext(ctx); ok {
+			return q.Where(column+" = ?", owner)
+		}
+
+		if domain.IsSystem(ctx) {
+			return q
+		}
+
+		return q.Where("1 = 0")
+	}
+}
@@ -0,0 +1,440 @@
+package taskrps
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"strconv"
+	"time"
+)
+
+const (
+	taskCacheKey       = "task:"
+	taskListCacheKey   = "task:list:"
+	taskListVersionKey = "task:list:version"
+)
+
+type taskCache struct {
+	redis *redis.Client
+	ttl   time.Duration
+	next  ports.TaskRepository
+	// written is set in a transaction, it collects the ids of the tasks to invalidate once the
+	// transaction is over
+	written *[]string
+}
+
+type taskListCache struct {
+	Tasks []*domain.Task
+	Total int64
+}
+
+// NewTaskCache wraps next with a read-through redis cache. Cache errors never fail a
+// request, the call just falls through to next.
+func NewTaskCache(redis *redis.Client, ttl time.Duration, next ports.TaskRepository) ports.TaskRepository {
+	return &taskCache{
+		redis: redis,
+		ttl:   ttl,
+		next:  next,
+	}
+}
+
+func (instance *taskCache) Create(ctx context.Context, task *domain.Task) error {
+	if err := instance.next.Create(ctx, task); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx)
+
+	return nil
+}
+
+func (instance *taskCache) Update(ctx context.Context, task *domain.Task) error {
+	if err := instance.next.Update(ctx, task); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(task.ID, 10))
+
+	return nil
+}
+
+func (instance *taskCache) Delete(ctx context.Context, task *domain.Task) error {
+	if err := instance.next.Delete(ctx, task); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(task.ID, 10))
+
+	return nil
+}
+
+func (instance *taskCache) SaveOccurrence(ctx context.Context, task *domain.Task, next *domain.Task) error {
+	if err := instance.next.SaveOccurrence(ctx, task, next); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(task.ID, 10))
+
+	return nil
+}
+
+func (instance *taskCache) Restore(ctx context.Context, task *domain.Task) error {
+	if err := instance.next.Restore(ctx, task); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(task.ID, 10))
+
+	return nil
+}
+
+// Purge also invalidates the tasks the purged task blocked, it is removed from their blockers
+func (instance *taskCache) Purge(ctx context.Context, task *domain.Task) error {
+	blockedIDs, err := instance.next.GetAllBlockedIDs(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := instance.next.Purge(ctx, task); err != nil {
+		return err
+	}
+
+	ids := []string{strconv.FormatUint(task.ID, 10)}
+	for _, blockedID := range blockedIDs {
+		ids = append(ids, strconv.FormatUint(blockedID, 10))
+	}
+	instance.invalidate(ctx, ids...)
+
+	return nil
+}
+
+// GetAllDueRecurring isn't cached, it is only read by the recurrence worker
+func (instance *taskCache) GetAllDueRecurring(ctx context.Context, now time.Time, limit int) ([]*domain.Task, error) {
+	return instance.next.GetAllDueRecurring(ctx, now, limit)
+}
+
+// GetAllDueReminders isn't cached, it is only read by the reminder worker
+func (instance *taskCache) GetAllDueReminders(ctx context.Context, now time.Time, offset time.Duration, limit int) ([]*domain.Task, error) {
+	return instance.next.GetAllDueReminders(ctx, now, offset, limit)
+}
+
+// CreateReminder doesn't change any cached task
+func (instance *taskCache) CreateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	return instance.next.CreateReminder(ctx, reminder)
+}
+
+// GetAllRetryReminders isn't cached, it is only read by the reminder worker
+func (instance *taskCache) GetAllRetryReminders(ctx context.Context, now time.Time, limit int) ([]*domain.Reminder, error) {
+	return instance.next.GetAllRetryReminders(ctx, now, limit)
+}
+
+// ClaimReminder doesn't change any cached task
+func (instance *taskCache) ClaimReminder(ctx context.Context, reminder *domain.Reminder, now time.Time) error {
+	return instance.next.ClaimReminder(ctx, reminder, now)
+}
+
+func (instance *taskCache) UpdateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	return instance.next.UpdateReminder(ctx, reminder)
+}
+
+// GetTrashedByID isn't cached, the trash is rarely read
+func (instance *taskCache) GetTrashedByID(ctx context.Context, id string) (*domain.Task, error) {
+	return instance.next.GetTrashedByID(ctx, id)
+}
+
+// CountByProject isn't cached, the counts change with every write of a task
+func (instance *taskCache) CountByProject(ctx context.Context, projectIDs []uint64) (map[uint64]*domain.ProjectCount, error) {
+	return instance.next.CountByProject(ctx, projectIDs)
+}
+
+// CreateDependency changes the blockers of the cached task
+func (instance *taskCache) CreateDependency(ctx context.Context, dependency *domain.TaskDependency) error {
+	if err := instance.next.CreateDependency(ctx, dependency); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(dependency.TaskID, 10))
+
+	return nil
+}
+
+// DeleteDependency changes the blockers of the cached task
+func (instance *taskCache) DeleteDependency(ctx context.Context, dependency *domain.TaskDependency) error {
+	if err := instance.next.DeleteDependency(ctx, dependency); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(dependency.TaskID, 10))
+
+	return nil
+}
+
+// GetAllBlockers isn't cached, the blockers are read before changing a dependency or finishing a task
+func (instance *taskCache) GetAllBlockers(ctx context.Context, taskIDs []uint64) ([]*domain.Task, error) {
+	return instance.next.GetAllBlockers(ctx, taskIDs)
+}
+
+// GetAllBlockedIDs isn't cached, it is read before purging a task
+func (instance *taskCache) GetAllBlockedIDs(ctx context.Context, blockerID uint64) ([]uint64, error) {
+	return instance.next.GetAllBlockedIDs(ctx, blockerID)
+}
+
+// LockDependencies doesn't change any cached task
+func (instance *taskCache) LockDependencies(ctx context.Context) error {
+	return instance.next.LockDependencies(ctx)
+}
+
+// GetAllByParent isn't cached, the subtasks are read before changing their tree
+func (instance *taskCache) GetAllByParent(ctx context.Context, parentIDs []uint64) ([]*domain.Task, error) {
+	return instance.next.GetAllByParent(ctx, parentIDs)
+}
+
+// GetAllTags isn't cached, the counts change with every write of a task
+func (instance *taskCache) GetAllTags(ctx context.Context) ([]*domain.TagCount, error) {
+	return instance.next.GetAllTags(ctx)
+}
+
+// GetAllAuditLogWithPaginate isn't cached, the history is rarely read
+func (instance *taskCache) GetAllAuditLogWithPaginate(ctx context.Context, id string, params *domain.AuditLogParams) ([]*domain.AuditLog, int64, error) {
+	return instance.next.GetAllAuditLogWithPaginate(ctx, id, params)
+}
+
+func (instance *taskCache) CreateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	if err := instance.next.CreateObjective(ctx, task, objective); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(task.ID, 10))
+
+	return nil
+}
+
+func (instance *taskCache) UpdateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	if err := instance.next.UpdateObjective(ctx, task, objective); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(task.ID, 10))
+
+	return nil
+}
+
+func (instance *taskCache) DeleteObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	if err := instance.next.DeleteObjective(ctx, task, objective); err != nil {
+		return err
+	}
+
+	instance.invalidate(ctx, strconv.FormatUint(task.ID, 10))
+
+	return nil
+}
+
+// Transaction doesn't read nor write the cache in fn since its writes may be rolled back, the tasks
+// it wrote are invalidated once it is over
+func (instance *taskCache) Transaction(ctx context.Context, fn func(repo ports.TaskRepository) error) error {
+	// a nested transaction leaves the invalidation to the outer one
+	nested := instance.written != nil
+	written := instance.written
+	if !nested {
+		written = &[]string{}
+	}
+
+	err := instance.next.Transaction(ctx, func(repo ports.TaskRepository) error {
+		return fn(&taskCache{
+			redis:   instance.redis,
+			ttl:     instance.ttl,
+			next:    repo,
+			written: written,
+		})
+	})
+
+	if !nested {
+		instance.invalidate(ctx, *written...)
+	}
+
+	return err
+}
+
+// GetOneByID is getting task from cache, falling back to the next repository on a miss
+func (instance *taskCache) GetOneByID(ctx context.Context, id string) (*domain.Task, error) {
+	// the cached task is shared by every user, it is only returned to its owner
+	var task *domain.Task
+	if instance.get(ctx, taskCacheKey+id, &task) && task != nil {
+		if !domain.OwnedBy(ctx, task.OwnerID) {
+			return nil, nil
+		}
+
+		return task, nil
+	}
+
+	// the version is read first, a task written meanwhile is not cached as it was before the write
+	version, versionErr := instance.listVersion(ctx)
+
+	task, err := instance.next.GetOneByID(ctx, id)
+	if err != nil || task == nil {
+		return task, err
+	}
+
+	if versionErr == nil {
+		instance.setUnchanged(ctx, taskCacheKey+id, task, version)
+	}
+
+	return task, nil
+}
+
+// GetAllWithPaginate is getting a page of tasks from cache, falling back to the next repository on a miss
+func (instance *taskCache) GetAllWithPaginate(ctx context.Context, params *domain.TaskParams) ([]*domain.Task, int64, error) {
+	key, err := instance.listKey(ctx, params)
+	if err != nil {
+		return instance.next.GetAllWithPaginate(ctx, params)
+	}
+
+	var cached taskListCache
+	if instance.get(ctx, key, &cached) {
+		return cached.Tasks, cached.Total, nil
+	}
+
+	tasks, total, err := instance.next.GetAllWithPaginate(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	instance.set(ctx, key, taskListCache{
+		Tasks: tasks,
+		Total: total,
+	})
+
+	return tasks, total, nil
+}
+
+// GetAllWithCursor is getting a page of tasks from cache, falling back to the next repository on a miss
+func (instance *taskCache) GetAllWithCursor(ctx context.Context, params *domain.TaskParams, cursor *domain.TaskCursor) ([]*domain.Task, error) {
+	key, err := instance.listKey(ctx, params)
+	if err != nil {
+		return instance.next.GetAllWithCursor(ctx, params, cursor)
+	}
+
+	var cached taskListCache
+	if instance.get(ctx, key, &cached) {
+		return cached.Tasks, nil
+	}
+
+	tasks, err := instance.next.GetAllWithCursor(ctx, params, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.set(ctx, key, taskListCache{
+		Tasks: tasks,
+	})
+
+	return tasks, nil
+}
+
+// listKey builds the key of a page from its owner, params and the current list version, bumping
+// the version makes every cached page unreachable until they expire
+func (instance *taskCache) listKey(ctx context.Context, params *domain.TaskParams) (string, error) {
+	version, err := instance.listVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(raw)
+
+	// a ctx without a user sees every task or none
+	owner := "none"
+	if id, ok := domain.OwnerFromContext(ctx); ok {
+		owner = strconv.FormatUint(id, 10)
+	} else if domain.IsSystem(ctx) {
+		owner = "all"
+	}
+
+	return taskListCacheKey + version + ":" + owner + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// listVersion is bumped by every write, an unset version is empty
+func (instance *taskCache) listVersion(ctx context.Context) (string, error) {
+	version, err := instance.redis.Get(ctx, taskListVersionKey).Result()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// invalidate drops the cached tasks of ids and every cached page
+func (instance *taskCache) invalidate(ctx context.Context, ids ...string) {
+	if instance.written != nil {
+		*instance.written = append(*instance.written, ids...)
+		return
+	}
+
+	pipe := instance.redis.TxPipeline()
+	for _, id := range ids {
+		pipe.Del(ctx, taskCacheKey+id)
+	}
+	pipe.Incr(ctx, taskListVersionKey)
+	_, _ = pipe.Exec(ctx)
+}
+
+func (instance *taskCache) get(ctx context.Context, key string, dest interface{}) bool {
+	if instance.written != nil {
+		return false
+	}
+
+	raw, err := instance.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(raw, dest) == nil
+}
+
+func (instance *taskCache) set(ctx context.Context, key string, value interface{}) {
+	if instance.written != nil {
+		return
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	_ = instance.redis.Set(ctx, key, raw, instance.ttl).Err()
+}
+
+// setUnchanged is like set but only caches value while the list version is still version, the write
+// bumping it meanwhile already dropped value
+func (instance *taskCache) setUnchanged(ctx context.Context, key string, value interface{}, version string) {
+	if instance.written != nil {
+		return
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	_ = instance.redis.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, taskListVersionKey).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if current != version {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, key, raw, instance.ttl).Err()
+		})
+		return err
+	}, taskListVersionKey)
+}
@@ -0,0 +1,147 @@
+package taskrps
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/outboxrps"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// racingRepository calls during once, after the task was read and before it is returned
+type racingRepository struct {
+	ports.TaskRepository
+	during func()
+}
+
+func (instance *racingRepository) GetOneByID(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := instance.TaskRepository.GetOneByID(ctx, id)
+
+	if during := instance.during; during != nil {
+		instance.during = nil
+		during()
+	}
+
+	return task, err
+}
+
+func newTestCache(t *testing.T) (*taskCache, *racingRepository, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	next := &racingRepository{TaskRepository: NewTaskMemory(outboxrps.NewOutboxMemory())}
+	cache := NewTaskCache(redis.NewClient(&redis.Options{Addr: server.Addr()}), time.Minute, next).(*taskCache)
+
+	return cache, next, server
+}
+
+func createCachedTask(t *testing.T, cache *taskCache, ctx context.Context, title string) (*domain.Task, string) {
+	t.Helper()
+
+	task := &domain.Task{OwnerID: 1, Title: title, ActionTime: time.Now(), Version: 1}
+	require.NoError(t, cache.Create(ctx, task))
+
+	return task, strconv.FormatUint(task.ID, 10)
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	cache, _, server := newTestCache(t)
+	ctx := domain.WithOwner(context.Background(), 1)
+	task, id := createCachedTask(t, cache, ctx, "pay rent")
+
+	_, err := cache.GetOneByID(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, server.Exists(taskCacheKey+id))
+
+	params := &domain.TaskParams{Page: 1, Limit: 10}
+	tasks, _, err := cache.GetAllWithPaginate(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	// the update drops the cached task and every cached page
+	stored, err := cache.GetOneByID(ctx, id)
+	require.NoError(t, err)
+	stored.Title = "pay the rent"
+	require.NoError(t, cache.Update(ctx, stored))
+	assert.False(t, server.Exists(taskCacheKey+id))
+
+	read, err := cache.GetOneByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "pay the rent", read.Title)
+
+	tasks, _, err = cache.GetAllWithPaginate(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "pay the rent", tasks[0].Title)
+
+	// a page cached before a create doesn't hide the new task
+	createCachedTask(t, cache, ctx, "pay bills")
+	tasks, _, err = cache.GetAllWithPaginate(ctx, params)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	// the cached task is only returned to its owner
+	other, err := cache.GetOneByID(domain.WithOwner(context.Background(), 2), strconv.FormatUint(task.ID, 10))
+	require.NoError(t, err)
+	assert.Nil(t, other)
+}
+
+func TestCacheSkipsTaskWrittenWhileRead(t *testing.T) {
+	cache, next, server := newTestCache(t)
+	ctx := domain.WithOwner(context.Background(), 1)
+	_, id := createCachedTask(t, cache, ctx, "file taxes")
+
+	// another request updates the task between the read of this one and its caching
+	next.during = func() {
+		stored, err := next.TaskRepository.GetOneByID(ctx, id)
+		require.NoError(t, err)
+		stored.Title = "file the taxes"
+		require.NoError(t, cache.Update(ctx, stored))
+	}
+
+	stale, err := cache.GetOneByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "file taxes", stale.Title)
+	assert.False(t, server.Exists(taskCacheKey+id))
+
+	read, err := cache.GetOneByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "file the taxes", read.Title)
+}
+
+func TestCacheTransaction(t *testing.T) {
+	cache, _, server := newTestCache(t)
+	ctx := domain.WithOwner(context.Background(), 1)
+	_, id := createCachedTask(t, cache, ctx, "book flight")
+
+	_, err := cache.GetOneByID(ctx, id)
+	require.NoError(t, err)
+	version, err := server.Get(taskListVersionKey)
+	require.NoError(t, err)
+
+	err = cache.Transaction(ctx, func(repo ports.TaskRepository) error {
+		stored, err := repo.GetOneByID(ctx, id)
+		require.NoError(t, err)
+		stored.Title = "book train"
+		require.NoError(t, repo.Update(ctx, stored))
+
+		// the cache is left alone until the transaction is over
+		assert.True(t, server.Exists(taskCacheKey+id))
+		current, err := server.Get(taskListVersionKey)
+		require.NoError(t, err)
+		assert.Equal(t, version, current)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.False(t, server.Exists(taskCacheKey+id))
+
+	read, err := cache.GetOneByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "book train", read.Title)
+}
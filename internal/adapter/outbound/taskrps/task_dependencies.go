@@ -0,0 +1,137 @@
+package taskrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/adapter/outbound/scope"
+	"github.com/todo-list/internal/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dependencyLockKey is the advisory lock serializing the dependency changes, a cycle can be closed by
+// two dependencies not sharing any task so locking their rows isn't enough
+const dependencyLockKey = 7340032
+
+// loadBlockers fills the blockers of tasks in one query, the tasks without blockers get an empty list
+func loadBlockers(tx *gorm.DB, tasks ...*domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := map[uint64]*domain.Task{}
+	var ids []uint64
+	for _, task := range tasks {
+		task.BlockedBy = []uint64{}
+		byID[task.ID] = task
+		ids = append(ids, task.ID)
+	}
+
+	var dependencies []*domain.TaskDependency
+	if err := tx.Debug().Where("task_id IN ?", ids).Order("blocker_id").Find(&dependencies).Error; err != nil {
+		return err
+	}
+
+	for _, dependency := range dependencies {
+		if task, ok := byID[dependency.TaskID]; ok {
+			task.BlockedBy = append(task.BlockedBy, dependency.BlockerID)
+		}
+	}
+
+	return nil
+}
+
+// filterBlocked keeps the tasks having an open blocker, or the ones without when blocked is false
+func filterBlocked(q *gorm.DB, blocked bool) *gorm.DB {
+	exists := `EXISTS (SELECT 1 FROM task_dependencies
+		JOIN tasks AS blockers ON blockers.id = task_dependencies.blocker_id
+		WHERE task_dependencies.task_id = tasks.id AND blockers.deleted_at IS NULL AND blockers.is_finished = ?)`
+
+	if blocked {
+		return q.Where(exists, false)
+	}
+
+	return q.Where("NOT "+exists, false)
+}
+
+// CreateDependency is blocking a task by another, it fails with domain.ErrDependencyExists when it
+// already is and with domain.ErrDependencyMissing when one of them was purged
+func (instance *taskPostgres) CreateDependency(ctx context.Context, dependency *domain.TaskDependency) error {
+	var count int64
+	if err := instance.postgres.Debug().Model(&domain.Task{}).
+		Where("id IN ?", []uint64{dependency.TaskID, dependency.BlockerID}).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count < 2 {
+		return domain.ErrDependencyMissing
+	}
+
+	result := instance.postgres.Debug().
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(dependency)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDependencyExists
+	}
+
+	return nil
+}
+
+// DeleteDependency is unblocking a task, it fails with domain.ErrDependencyNotFound when it wasn't
+// blocked by the blocker
+func (instance *taskPostgres) DeleteDependency(ctx context.Context, dependency *domain.TaskDependency) error {
+	result := instance.postgres.Debug().
+		Where("task_id = ? AND blocker_id = ?", dependency.TaskID, dependency.BlockerID).
+		Delete(&domain.TaskDependency{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDependencyNotFound
+	}
+
+	return nil
+}
+
+// GetAllBlockers is getting the tasks blocking the tasks with the given ids, trashed ones included
+func (instance *taskPostgres) GetAllBlockers(ctx context.Context, taskIDs []uint64) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	if err := instance.postgres.Debug().Scopes(scope.Owner(ctx)).
+		Where("id IN (SELECT blocker_id FROM task_dependencies WHERE task_id IN ?)", taskIDs).
+		Order("id").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	if err := loadBlockers(instance.postgres, tasks...); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// GetAllBlockedIDs is getting the ids of the tasks blocked by the task with blockerID, whoever owns them
+func (instance *taskPostgres) GetAllBlockedIDs(ctx context.Context, blockerID uint64) ([]uint64, error) {
+	var ids []uint64
+
+	if err := instance.postgres.Debug().Model(&domain.TaskDependency{}).
+		Where("blocker_id = ?", blockerID).
+		Order("task_id").
+		Pluck("task_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// LockDependencies is released when the transaction is over, it must be called in one
+func (instance *taskPostgres) LockDependencies(ctx context.Context) error {
+	return instance.postgres.Debug().Exec("SELECT pg_advisory_xact_lock(?)", dependencyLockKey).Error
+}
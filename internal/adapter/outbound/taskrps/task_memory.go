@@ -0,0 +1,1264 @@
+package taskrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type taskMemory struct {
+	mu           sync.RWMutex
+	taskSeq      uint64
+	objectiveSeq uint64
+	reminderSeq  uint64
+	auditSeq     uint64
+	tasks        map[uint64]*domain.Task
+	reminders    map[reminderKey]*domain.Reminder
+	audits       []*domain.AuditLog
+	// tags is the tags created by each owner, they are kept once no task has them like the tags table
+	tags   map[uint64]map[string]bool
+	outbox ports.OutboxRepository
+	// undo is set in a transaction, the writes are recorded in it so they can be rolled back
+	undo *undoLog
+}
+
+// reminderKey is unique per reminder, same as the unique index of the reminders table
+type reminderKey struct {
+	taskID        uint64
+	offsetSeconds int64
+	actionTime    int64
+}
+
+// NewTaskMemory writes the events of the tasks to outbox while holding its lock
+func NewTaskMemory(outbox ports.OutboxRepository) ports.TaskRepository {
+	return &taskMemory{
+		tasks:     map[uint64]*domain.Task{},
+		reminders: map[reminderKey]*domain.Reminder{},
+		tags:      map[uint64]map[string]bool{},
+		outbox:    outbox,
+	}
+}
+
+// Create is creating new task and objectives
+func (instance *taskMemory) Create(ctx context.Context, task *domain.Task) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	return instance.createTask(ctx, task)
+}
+
+// Update is updating task and objectives
+func (instance *taskMemory) Update(ctx context.Context, task *domain.Task) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	return instance.updateTask(ctx, task)
+}
+
+// SaveOccurrence is updating task like Update and creating next, the occurrence following it
+func (instance *taskMemory) SaveOccurrence(ctx context.Context, task *domain.Task, next *domain.Task) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	if err := instance.updateTask(ctx, task); err != nil {
+		return err
+	}
+
+	if err := instance.createTask(ctx, next); err != nil {
+		return err
+	}
+
+	// the next occurrence is linked without a new version, task was just saved
+	nextID := next.ID
+	task.NextID = &nextID
+	instance.tasks[task.ID].NextID = &nextID
+
+	return nil
+}
+
+// Transaction runs fn on a view writing through to the repository, which stays locked meanwhile. The
+// writes are undone when fn fails, a transaction started on the view is nested and only undoes its own.
+func (instance *taskMemory) Transaction(ctx context.Context, fn func(repo ports.TaskRepository) error) error {
+	if buffer, nested := instance.outbox.(*outboxBuffer); nested {
+		return instance.undoable(fn, buffer)
+	}
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	buffer := &outboxBuffer{OutboxRepository: instance.outbox}
+	tx := &taskMemory{
+		taskSeq:      instance.taskSeq,
+		objectiveSeq: instance.objectiveSeq,
+		reminderSeq:  instance.reminderSeq,
+		auditSeq:     instance.auditSeq,
+		tasks:        instance.tasks,
+		reminders:    instance.reminders,
+		audits:       instance.audits,
+		tags:         instance.tags,
+		outbox:       buffer,
+	}
+	if err := tx.undoable(func(repo ports.TaskRepository) error {
+		if err := fn(repo); err != nil {
+			return err
+		}
+		if len(buffer.events) == 0 {
+			return nil
+		}
+
+		return instance.outbox.CreateEvents(ctx, buffer.events)
+	}, buffer); err != nil {
+		return err
+	}
+
+	instance.taskSeq = tx.taskSeq
+	instance.objectiveSeq = tx.objectiveSeq
+	instance.reminderSeq = tx.reminderSeq
+	instance.auditSeq = tx.auditSeq
+	instance.audits = tx.audits
+
+	return nil
+}
+
+// undoable runs fn on the repository of a transaction and rolls its writes back when it fails, they
+// are kept in the undo log of the outer transaction when it succeeds
+func (instance *taskMemory) undoable(fn func(repo ports.TaskRepository) error, buffer *outboxBuffer) error {
+	instance.mu.Lock()
+	undo := &undoLog{
+		parent:       instance.undo,
+		taskSeq:      instance.taskSeq,
+		objectiveSeq: instance.objectiveSeq,
+		reminderSeq:  instance.reminderSeq,
+		auditSeq:     instance.auditSeq,
+		audits:       len(instance.audits),
+		events:       len(buffer.events),
+		tasks:        map[uint64]*domain.Task{},
+		reminders:    map[reminderKey]*domain.Reminder{},
+		tags:         map[uint64][]string{},
+	}
+	instance.undo = undo
+	instance.mu.Unlock()
+
+	err := fn(instance)
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	instance.undo = undo.parent
+	if err != nil {
+		instance.rollBack(undo, buffer)
+		return err
+	}
+	undo.mergeInto(instance.undo)
+
+	return nil
+}
+
+// undoLog is what a transaction wrote, the tasks and reminders are the ones stored before their first
+// write in the transaction, nil when they didn't exist
+type undoLog struct {
+	parent       *undoLog
+	taskSeq      uint64
+	objectiveSeq uint64
+	reminderSeq  uint64
+	auditSeq     uint64
+	audits       int
+	events       int
+	tasks        map[uint64]*domain.Task
+	reminders    map[reminderKey]*domain.Reminder
+	// tags are the tags created in the transaction by each owner
+	tags map[uint64][]string
+}
+
+// mergeInto keeps the writes in the log of the outer transaction, its own records are older
+func (undo *undoLog) mergeInto(outer *undoLog) {
+	if outer == nil {
+		return
+	}
+
+	for id, task := range undo.tasks {
+		if _, ok := outer.tasks[id]; !ok {
+			outer.tasks[id] = task
+		}
+	}
+	for key, reminder := range undo.reminders {
+		if _, ok := outer.reminders[key]; !ok {
+			outer.reminders[key] = reminder
+		}
+	}
+	for owner, tags := range undo.tags {
+		outer.tags[owner] = append(outer.tags[owner], tags...)
+	}
+}
+
+// rollBack restores the state undo was started from, must be called with the lock held
+func (instance *taskMemory) rollBack(undo *undoLog, buffer *outboxBuffer) {
+	for id, task := range undo.tasks {
+		if task == nil {
+			delete(instance.tasks, id)
+			continue
+		}
+		instance.tasks[id] = task
+	}
+	for key, reminder := range undo.reminders {
+		if reminder == nil {
+			delete(instance.reminders, key)
+			continue
+		}
+		instance.reminders[key] = reminder
+	}
+	for owner, tags := range undo.tags {
+		for _, tag := range tags {
+			delete(instance.tags[owner], tag)
+		}
+	}
+
+	instance.taskSeq = undo.taskSeq
+	instance.objectiveSeq = undo.objectiveSeq
+	instance.reminderSeq = undo.reminderSeq
+	instance.auditSeq = undo.auditSeq
+	instance.audits = instance.audits[:undo.audits]
+	buffer.events = buffer.events[:undo.events]
+}
+
+// touchTask records the stored task before a transaction writes it, must be called with the lock held
+func (instance *taskMemory) touchTask(id uint64) {
+	if instance.undo == nil {
+		return
+	}
+	if _, ok := instance.undo.tasks[id]; ok {
+		return
+	}
+
+	var stored *domain.Task
+	if task, ok := instance.tasks[id]; ok {
+		stored = copyTask(task)
+	}
+	instance.undo.tasks[id] = stored
+}
+
+// touchReminder records the stored reminder before a transaction writes it, must be called with the
+// lock held
+func (instance *taskMemory) touchReminder(key reminderKey) {
+	if instance.undo == nil {
+		return
+	}
+	if _, ok := instance.undo.reminders[key]; ok {
+		return
+	}
+
+	var stored *domain.Reminder
+	if reminder, ok := instance.reminders[key]; ok {
+		copied := *reminder
+		stored = &copied
+	}
+	instance.undo.reminders[key] = stored
+}
+
+// outboxBuffer holds the events written in a transaction until it is committed
+type outboxBuffer struct {
+	ports.OutboxRepository
+	events []*domain.OutboxEvent
+}
+
+func (instance *outboxBuffer) CreateEvents(ctx context.Context, events []*domain.OutboxEvent) error {
+	instance.events = append(instance.events, events...)
+	return nil
+}
+
+// createTask must be called with the lock held
+func (instance *taskMemory) createTask(ctx context.Context, task *domain.Task) error {
+	now := time.Now()
+
+	instance.taskSeq++
+	task.ID = instance.taskSeq
+	instance.touchTask(task.ID)
+	if task.Version == 0 {
+		task.Version = 1
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now
+	}
+	task.UpdatedAt = now
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+	task.BlockedBy = []uint64{}
+
+	instance.saveObjectives(task)
+	instance.saveTags(task)
+	if err := instance.saveEvents(ctx, task); err != nil {
+		return err
+	}
+	instance.tasks[task.ID] = copyTask(task)
+
+	return instance.saveAudit(ctx, domain.AuditCreate, nil, task.ID)
+}
+
+// updateTask must be called with the lock held
+func (instance *taskMemory) updateTask(ctx context.Context, task *domain.Task) error {
+	stored, ok := instance.tasks[task.ID]
+	if !ok || stored.Version != task.Version {
+		return domain.ErrTaskVersionConflict
+	}
+	before := copyTask(stored)
+	instance.touchTask(task.ID)
+
+	// objectives are only diffed when a list is given, same as postgres
+	if task.Objective != nil {
+		for _, obj := range task.Objective {
+			if stored.GetObjective(obj.ID) == nil {
+				instance.objectiveSeq++
+				obj.ID = instance.objectiveSeq
+			}
+			obj.TaskID = task.ID
+		}
+	} else {
+		task.Objective = stored.Objective
+	}
+
+	// tags are only replaced when a list is given, same as postgres
+	if task.Tags != nil {
+		instance.saveTags(task)
+	} else {
+		task.Tags = stored.Tags
+	}
+
+	// the blockers are only changed by CreateDependency & DeleteDependency
+	task.BlockedBy = stored.BlockedBy
+	// the next occurrence is only linked by SaveOccurrence
+	task.NextID = stored.NextID
+
+	task.UpdatedAt = time.Now()
+	task.Version++
+	task.DeletedAt = stored.DeletedAt
+	if err := instance.saveEvents(ctx, task); err != nil {
+		return err
+	}
+	instance.tasks[task.ID] = copyTask(task)
+
+	return instance.saveAudit(ctx, domain.AuditUpdate, before, task.ID)
+}
+
+// GetOneByID is getting task by id and its objectives, trashed tasks are not found
+func (instance *taskMemory) GetOneByID(ctx context.Context, id string) (*domain.Task, error) {
+	return instance.getOne(ctx, id, false)
+}
+
+// GetTrashedByID is getting a trashed task by id and its objectives
+func (instance *taskMemory) GetTrashedByID(ctx context.Context, id string) (*domain.Task, error) {
+	return instance.getOne(ctx, id, true)
+}
+
+func (instance *taskMemory) getOne(ctx context.Context, id string, trashed bool) (*domain.Task, error) {
+	taskID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	task, ok := instance.tasks[taskID]
+	if !ok || (task.DeletedAt != nil) != trashed || !domain.OwnedBy(ctx, task.OwnerID) {
+		return nil, nil
+	}
+
+	return copyTask(task), nil
+}
+
+// Delete is moving task to the trash, its objectives are kept so it can be restored
+func (instance *taskMemory) Delete(ctx context.Context, task *domain.Task) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[task.ID]
+	if !ok || stored.Version != task.Version {
+		return domain.ErrTaskVersionConflict
+	}
+	before := copyTask(stored)
+	instance.touchTask(task.ID)
+
+	now := time.Now()
+	task.Version++
+	task.DeletedAt = &now
+	if err := instance.saveEvents(ctx, task); err != nil {
+		return err
+	}
+	stored.Version = task.Version
+	stored.DeletedAt = &now
+
+	return instance.saveAudit(ctx, domain.AuditDelete, before, task.ID)
+}
+
+// Restore is moving task out of the trash
+func (instance *taskMemory) Restore(ctx context.Context, task *domain.Task) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[task.ID]
+	if !ok || stored.Version != task.Version {
+		return domain.ErrTaskVersionConflict
+	}
+	before := copyTask(stored)
+	instance.touchTask(task.ID)
+
+	task.Version++
+	task.DeletedAt = nil
+	if err := instance.saveEvents(ctx, task); err != nil {
+		return err
+	}
+	stored.Version = task.Version
+	stored.DeletedAt = nil
+
+	return instance.saveAudit(ctx, domain.AuditRestore, before, task.ID)
+}
+
+// Purge is permanently deleting task and its objectives
+func (instance *taskMemory) Purge(ctx context.Context, task *domain.Task) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[task.ID]
+	if !ok || stored.Version != task.Version {
+		return domain.ErrTaskVersionConflict
+	}
+	before := copyTask(stored)
+	instance.touchTask(task.ID)
+
+	delete(instance.tasks, task.ID)
+	for id, other := range instance.tasks {
+		if blockedBy := removeBlocker(other.BlockedBy, task.ID); len(blockedBy) != len(other.BlockedBy) {
+			instance.touchTask(id)
+			other.BlockedBy = blockedBy
+		}
+	}
+	for key := range instance.reminders {
+		if key.taskID == task.ID {
+			instance.touchReminder(key)
+			delete(instance.reminders, key)
+		}
+	}
+
+	return instance.saveAudit(ctx, domain.AuditPurge, before, task.ID)
+}
+
+func (instance *taskMemory) GetAllWithPaginate(ctx context.Context, params *domain.TaskParams) ([]*domain.Task, int64, error) {
+	filtered := instance.filterTasks(ctx, params, nil)
+
+	sorts := params.GetSorts()
+	sort.Slice(filtered, func(i, j int) bool {
+		return lessTask(filtered[i], filtered[j], sorts, params.Q != nil)
+	})
+
+	total := int64(len(filtered))
+
+	offset := params.Limit * (params.Page - 1)
+	if offset < 0 || offset >= len(filtered) {
+		return nil, total, nil
+	}
+
+	end := offset + params.Limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+
+	return filtered[offset:end], total, nil
+}
+
+// CountByProject is counting the open, finished and trashed tasks of each project, the projects
+// without tasks are missing from the map
+func (instance *taskMemory) CountByProject(ctx context.Context, projectIDs []uint64) (map[uint64]*domain.ProjectCount, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	wanted := map[uint64]bool{}
+	for _, projectID := range projectIDs {
+		wanted[projectID] = true
+	}
+
+	byProject := map[uint64]*domain.ProjectCount{}
+	for _, task := range instance.tasks {
+		if task.ProjectID == nil || !wanted[*task.ProjectID] || !domain.OwnedBy(ctx, task.OwnerID) {
+			continue
+		}
+
+		count, ok := byProject[*task.ProjectID]
+		if !ok {
+			count = &domain.ProjectCount{ProjectID: *task.ProjectID}
+			byProject[*task.ProjectID] = count
+		}
+
+		switch {
+		case task.DeletedAt != nil:
+			count.Trashed++
+		case task.IsFinished:
+			count.Finished++
+		default:
+			count.Open++
+		}
+	}
+
+	return byProject, nil
+}
+
+// GetAllByParent is getting the subtasks of the tasks with the given ids, trashed ones included
+func (instance *taskMemory) GetAllByParent(ctx context.Context, parentIDs []uint64) ([]*domain.Task, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var tasks []*domain.Task
+	for _, task := range instance.tasks {
+		if !domain.OwnedBy(ctx, task.OwnerID) {
+			continue
+		}
+
+		for _, parentID := range parentIDs {
+			if task.IsChildOf(parentID) {
+				tasks = append(tasks, copyTask(task))
+				break
+			}
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+// GetAllDueRecurring is getting the recurring tasks whose action time passed and whose next
+// occurrence wasn't created yet
+func (instance *taskMemory) GetAllDueRecurring(ctx context.Context, now time.Time, limit int) ([]*domain.Task, error) {
+	instance.mu.RLock()
+	var due []*domain.Task
+	for _, task := range instance.tasks {
+		if task.Recurrence != nil && !task.HasNext && task.DeletedAt == nil && !task.ActionTime.After(now) {
+			due = append(due, copyTask(task))
+		}
+	}
+	instance.mu.RUnlock()
+
+	sorts := []domain.TaskSort{{Field: domain.SortActionTime}}
+	sort.Slice(due, func(i, j int) bool {
+		return lessTask(due[i], due[j], sorts, false)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+// GetAllDueReminders is getting the unfinished tasks whose action time is within offset from now
+// and whose reminder for this offset & action time wasn't claimed yet
+func (instance *taskMemory) GetAllDueReminders(ctx context.Context, now time.Time, offset time.Duration, limit int) ([]*domain.Task, error) {
+	instance.mu.RLock()
+	var due []*domain.Task
+	for _, task := range instance.tasks {
+		if task.DeletedAt != nil || task.IsFinished || !task.ActionTime.After(now) || task.ActionTime.After(now.Add(offset)) {
+			continue
+		}
+		if _, ok := instance.reminders[newReminderKey(domain.NewReminder(task, offset))]; ok {
+			continue
+		}
+		due = append(due, copyTask(task))
+	}
+	instance.mu.RUnlock()
+
+	sorts := []domain.TaskSort{{Field: domain.SortActionTime}}
+	sort.Slice(due, func(i, j int) bool {
+		return lessTask(due[i], due[j], sorts, false)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+// CreateReminder is claiming a reminder, it fails with domain.ErrReminderExists when it was already claimed
+func (instance *taskMemory) CreateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	key := newReminderKey(reminder)
+	if _, ok := instance.reminders[key]; ok {
+		return domain.ErrReminderExists
+	}
+
+	instance.reminderSeq++
+	reminder.ID = instance.reminderSeq
+	instance.touchReminder(key)
+	copied := *reminder
+	copied.Task = nil
+	instance.reminders[key] = &copied
+
+	return nil
+}
+
+// UpdateReminder is saving the delivery state of a reminder
+func (instance *taskMemory) UpdateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	key := newReminderKey(reminder)
+	if stored, ok := instance.reminders[key]; ok {
+		instance.touchReminder(key)
+		stored.Status = reminder.Status
+		stored.Error = reminder.Error
+		stored.Attempts = reminder.Attempts
+		stored.NextAttemptAt = reminder.NextAttemptAt
+		stored.SentAt = reminder.SentAt
+	}
+
+	return nil
+}
+
+// GetAllRetryReminders is getting the reminders not sent yet whose next attempt time came, with their
+// task when it wasn't purged
+func (instance *taskMemory) GetAllRetryReminders(ctx context.Context, now time.Time, limit int) ([]*domain.Reminder, error) {
+	instance.mu.RLock()
+	var due []*domain.Reminder
+	for _, stored := range instance.reminders {
+		if stored.Status == domain.ReminderSent || stored.NextAttemptAt == nil || stored.NextAttemptAt.After(now) {
+			continue
+		}
+
+		reminder := *stored
+		if task, ok := instance.tasks[reminder.TaskID]; ok {
+			reminder.Task = copyTask(task)
+		}
+		due = append(due, &reminder)
+	}
+	instance.mu.RUnlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(*due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+// ClaimReminder is saving the attempt of a reminder not sent yet, it fails with domain.ErrReminderExists
+// when it was sent or claimed again by another worker
+func (instance *taskMemory) ClaimReminder(ctx context.Context, reminder *domain.Reminder, now time.Time) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	key := newReminderKey(reminder)
+	stored, ok := instance.reminders[key]
+	if !ok || stored.Status == domain.ReminderSent || stored.NextAttemptAt == nil || stored.NextAttemptAt.After(now) {
+		return domain.ErrReminderExists
+	}
+
+	instance.touchReminder(key)
+	stored.Status = reminder.Status
+	stored.Attempts = reminder.Attempts
+	stored.NextAttemptAt = reminder.NextAttemptAt
+
+	return nil
+}
+
+func newReminderKey(reminder *domain.Reminder) reminderKey {
+	return reminderKey{
+		taskID:        reminder.TaskID,
+		offsetSeconds: reminder.OffsetSeconds,
+		actionTime:    reminder.ActionTime.Unix(),
+	}
+}
+
+// CreateObjective is creating one objective and saving the finished state of its task
+func (instance *taskMemory) CreateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[task.ID]
+	if !ok || stored.Version != task.Version {
+		return domain.ErrTaskVersionConflict
+	}
+	before := copyTask(stored)
+	instance.touchTask(task.ID)
+
+	instance.objectiveSeq++
+	objective.ID = instance.objectiveSeq
+	objective.TaskID = task.ID
+
+	copied := *objective
+	copied.Task = nil
+	stored.Objective = append(stored.Objective, &copied)
+	copyTaskState(stored, task)
+
+	if err := instance.saveAudit(ctx, domain.AuditUpdate, before, task.ID); err != nil {
+		return err
+	}
+
+	return instance.saveEvents(ctx, task)
+}
+
+// UpdateObjective is updating one objective and saving the finished state of its task
+func (instance *taskMemory) UpdateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[task.ID]
+	if !ok || stored.Version != task.Version {
+		return domain.ErrTaskVersionConflict
+	}
+	before := copyTask(stored)
+	instance.touchTask(task.ID)
+
+	if obj := stored.GetObjective(objective.ID); obj != nil {
+		obj.ObjectiveName = objective.ObjectiveName
+		obj.IsFinished = objective.IsFinished
+	}
+	copyTaskState(stored, task)
+
+	if err := instance.saveAudit(ctx, domain.AuditUpdate, before, task.ID); err != nil {
+		return err
+	}
+
+	return instance.saveEvents(ctx, task)
+}
+
+// DeleteObjective is deleting one objective and saving the finished state of its task
+func (instance *taskMemory) DeleteObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[task.ID]
+	if !ok || stored.Version != task.Version {
+		return domain.ErrTaskVersionConflict
+	}
+	before := copyTask(stored)
+	instance.touchTask(task.ID)
+
+	var objectives []*domain.Objective
+	for _, obj := range stored.Objective {
+		if obj.ID != objective.ID {
+			objectives = append(objectives, obj)
+		}
+	}
+	stored.Objective = objectives
+	copyTaskState(stored, task)
+
+	if err := instance.saveAudit(ctx, domain.AuditUpdate, before, task.ID); err != nil {
+		return err
+	}
+
+	return instance.saveEvents(ctx, task)
+}
+
+// copyTaskState copies the finished state of task to the stored one and bumps its version,
+// must be called with the lock held
+func copyTaskState(stored *domain.Task, task *domain.Task) {
+	task.UpdatedAt = time.Now()
+	task.Version++
+	stored.IsFinished = task.IsFinished
+	stored.UpdatedAt = task.UpdatedAt
+	stored.Version = task.Version
+}
+
+// saveAudit writes the audit log of a change of the task, before is the stored task copied before the
+// change. Must be called with the lock held.
+func (instance *taskMemory) saveAudit(ctx context.Context, action string, before *domain.Task, id uint64) error {
+	var after *domain.Task
+	if stored, ok := instance.tasks[id]; ok {
+		after = copyTask(stored)
+	}
+
+	audit, err := domain.NewAuditLog(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	instance.auditSeq++
+	audit.ID = instance.auditSeq
+	instance.audits = append(instance.audits, audit)
+
+	return nil
+}
+
+// GetAllAuditLogWithPaginate is getting the audit log of a task, newest first. It is kept after
+// the task was purged.
+func (instance *taskMemory) GetAllAuditLogWithPaginate(ctx context.Context, id string, params *domain.AuditLogParams) ([]*domain.AuditLog, int64, error) {
+	taskID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, 0, nil
+	}
+
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var audits []*domain.AuditLog
+	for i := len(instance.audits) - 1; i >= 0; i-- {
+		if instance.audits[i].TaskID == taskID && domain.OwnedBy(ctx, instance.audits[i].OwnerID) {
+			copied := *instance.audits[i]
+			audits = append(audits, &copied)
+		}
+	}
+
+	total := int64(len(audits))
+
+	offset := params.Limit * (params.Page - 1)
+	if offset < 0 || offset >= len(audits) {
+		return nil, total, nil
+	}
+
+	end := offset + params.Limit
+	if end > len(audits) {
+		end = len(audits)
+	}
+
+	return audits[offset:end], total, nil
+}
+
+// saveEvents writes the events recorded on task to the outbox, must be called with the lock held
+// so the events are in the same order as the changes
+func (instance *taskMemory) saveEvents(ctx context.Context, task *domain.Task) error {
+	events, err := task.PullEvents()
+	if err != nil {
+		return err
+	}
+
+	return instance.outbox.CreateEvents(ctx, events)
+}
+
+// saveObjectives assigns ids to the objectives of task, must be called with the lock held
+func (instance *taskMemory) saveObjectives(task *domain.Task) {
+	for _, obj := range task.Objective {
+		instance.objectiveSeq++
+		obj.ID = instance.objectiveSeq
+		obj.TaskID = task.ID
+	}
+}
+
+// CreateDependency is blocking a task by another, it fails with domain.ErrDependencyExists when it
+// already is and with domain.ErrDependencyMissing when one of them was purged
+func (instance *taskMemory) CreateDependency(ctx context.Context, dependency *domain.TaskDependency) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[dependency.TaskID]
+	if _, found := instance.tasks[dependency.BlockerID]; !ok || !found {
+		return domain.ErrDependencyMissing
+	}
+
+	for _, blockerID := range stored.BlockedBy {
+		if blockerID == dependency.BlockerID {
+			return domain.ErrDependencyExists
+		}
+	}
+
+	instance.touchTask(dependency.TaskID)
+	stored.BlockedBy = append(stored.BlockedBy, dependency.BlockerID)
+	sort.Slice(stored.BlockedBy, func(i, j int) bool {
+		return stored.BlockedBy[i] < stored.BlockedBy[j]
+	})
+
+	return nil
+}
+
+// DeleteDependency is unblocking a task, it fails with domain.ErrDependencyNotFound when it wasn't
+// blocked by the blocker
+func (instance *taskMemory) DeleteDependency(ctx context.Context, dependency *domain.TaskDependency) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.tasks[dependency.TaskID]
+	if !ok {
+		return domain.ErrDependencyNotFound
+	}
+
+	blockedBy := removeBlocker(stored.BlockedBy, dependency.BlockerID)
+	if len(blockedBy) == len(stored.BlockedBy) {
+		return domain.ErrDependencyNotFound
+	}
+	instance.touchTask(dependency.TaskID)
+	stored.BlockedBy = blockedBy
+
+	return nil
+}
+
+// GetAllBlockers is getting the tasks blocking the tasks with the given ids, trashed ones included
+func (instance *taskMemory) GetAllBlockers(ctx context.Context, taskIDs []uint64) ([]*domain.Task, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	blockerIDs := map[uint64]bool{}
+	for _, taskID := range taskIDs {
+		if task, ok := instance.tasks[taskID]; ok {
+			for _, blockerID := range task.BlockedBy {
+				blockerIDs[blockerID] = true
+			}
+		}
+	}
+
+	var tasks []*domain.Task
+	for blockerID := range blockerIDs {
+		if blocker, ok := instance.tasks[blockerID]; ok && domain.OwnedBy(ctx, blocker.OwnerID) {
+			tasks = append(tasks, copyTask(blocker))
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+// GetAllBlockedIDs is getting the ids of the tasks blocked by the task with blockerID, whoever owns them
+func (instance *taskMemory) GetAllBlockedIDs(ctx context.Context, blockerID uint64) ([]uint64, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var ids []uint64
+	for _, task := range instance.tasks {
+		for _, id := range task.BlockedBy {
+			if id == blockerID {
+				ids = append(ids, task.ID)
+			}
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids, nil
+}
+
+// LockDependencies has nothing to hold, the transactions of the memory repository hold its lock
+func (instance *taskMemory) LockDependencies(ctx context.Context) error {
+	return nil
+}
+
+// isBlocked reports whether one of the blockers of task is open, must be called with the lock held
+func (instance *taskMemory) isBlocked(task *domain.Task) bool {
+	var blockers []*domain.Task
+	for _, blockerID := range task.BlockedBy {
+		if blocker, ok := instance.tasks[blockerID]; ok {
+			blockers = append(blockers, blocker)
+		}
+	}
+
+	return task.IsBlockedBy(blockers)
+}
+
+func removeBlocker(blockedBy []uint64, blockerID uint64) []uint64 {
+	var kept []uint64
+	for _, id := range blockedBy {
+		if id != blockerID {
+			kept = append(kept, id)
+		}
+	}
+
+	if kept == nil && blockedBy != nil {
+		return []uint64{}
+	}
+
+	return kept
+}
+
+// saveTags records the tags of task as created by its owner, must be called with the lock held
+func (instance *taskMemory) saveTags(task *domain.Task) {
+	owned, ok := instance.tags[task.OwnerID]
+	if !ok {
+		owned = map[string]bool{}
+		instance.tags[task.OwnerID] = owned
+	}
+
+	for _, tag := range task.Tags {
+		if instance.undo != nil && !owned[tag] {
+			instance.undo.tags[task.OwnerID] = append(instance.undo.tags[task.OwnerID], tag)
+		}
+		owned[tag] = true
+	}
+}
+
+// GetAllTags is getting the tags with the number of the tasks labelled with each, the most used first
+func (instance *taskMemory) GetAllTags(ctx context.Context) ([]*domain.TagCount, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	byName := map[string]*domain.TagCount{}
+	var counts []*domain.TagCount
+	for owner, owned := range instance.tags {
+		if !domain.OwnedBy(ctx, owner) {
+			continue
+		}
+
+		for name := range owned {
+			if _, ok := byName[name]; !ok {
+				count := &domain.TagCount{Name: name}
+				byName[name] = count
+				counts = append(counts, count)
+			}
+		}
+	}
+
+	for _, task := range instance.tasks {
+		if task.DeletedAt != nil || !domain.OwnedBy(ctx, task.OwnerID) {
+			continue
+		}
+
+		for _, tag := range task.Tags {
+			if count, ok := byName[tag]; ok {
+				count.UsageCount++
+			}
+		}
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].UsageCount != counts[j].UsageCount {
+			return counts[i].UsageCount > counts[j].UsageCount
+		}
+
+		return counts[i].Name < counts[j].Name
+	})
+
+	return counts, nil
+}
+
+// matchTaskParams applies the same filters as taskPostgres.GetAllWithPaginate
+func matchTaskParams(task *domain.Task, params *domain.TaskParams) bool {
+	if (task.DeletedAt != nil) != params.Trashed {
+		return false
+	}
+	if params.Title != nil && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(*params.Title)) {
+		return false
+	}
+	if params.ActionTimeStart != nil && task.ActionTime.Before(time.Unix(int64(*params.ActionTimeStart), 0).UTC()) {
+		return false
+	}
+	if params.ActionTimeEnd != nil && task.ActionTime.After(time.Unix(int64(*params.ActionTimeEnd), 0).UTC()) {
+		return false
+	}
+	if params.IsFinished != nil && task.IsFinished != *params.IsFinished {
+		return false
+	}
+	if params.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *params.ProjectID) {
+		return false
+	}
+	if len(params.Priority) > 0 && !hasPriority(task, domain.ParsePriorities(params.Priority)) {
+		return false
+	}
+	if params.DueTimeStart != nil && (task.DueAt == nil || task.DueAt.Before(time.Unix(int64(*params.DueTimeStart), 0).UTC())) {
+		return false
+	}
+	if params.DueTimeEnd != nil && (task.DueAt == nil || task.DueAt.After(time.Unix(int64(*params.DueTimeEnd), 0).UTC())) {
+		return false
+	}
+	if tags := domain.NormalizeTags(params.Tags); len(tags) > 0 && !task.HasTags(tags, params.TagMode == domain.TagModeAll) {
+		return false
+	}
+
+	return true
+}
+
+func hasPriority(task *domain.Task, priorities []domain.Priority) bool {
+	for _, priority := range priorities {
+		if task.Priority == priority {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyTask returns a deep copy of task so stored rows can't be mutated by callers
+func copyTask(task *domain.Task) *domain.Task {
+	copied := *task
+	copied.Objective = nil
+	if task.DeletedAt != nil {
+		deletedAt := *task.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+	if task.RecurrenceStart != nil {
+		recurrenceStart := *task.RecurrenceStart
+		copied.RecurrenceStart = &recurrenceStart
+	}
+	if task.ProjectID != nil {
+		projectID := *task.ProjectID
+		copied.ProjectID = &projectID
+	}
+	if task.ParentID != nil {
+		parentID := *task.ParentID
+		copied.ParentID = &parentID
+	}
+	if task.NextID != nil {
+		nextID := *task.NextID
+		copied.NextID = &nextID
+	}
+	if task.DueAt != nil {
+		dueAt := *task.DueAt
+		copied.DueAt = &dueAt
+	}
+	if task.Tags != nil {
+		copied.Tags = append([]string{}, task.Tags...)
+	}
+	if task.BlockedBy != nil {
+		copied.BlockedBy = append([]uint64{}, task.BlockedBy...)
+	}
+
+	for _, obj := range task.Objective {
+		copiedObj := *obj
+		copiedObj.Task = nil
+		copied.Objective = append(copied.Objective, &copiedObj)
+	}
+
+	return &copied
+}
+
+func (instance *taskMemory) GetAllWithCursor(ctx context.Context, params *domain.TaskParams, cursor *domain.TaskCursor) ([]*domain.Task, error) {
+	filtered := instance.filterTasks(ctx, params, cursor)
+
+	sorts := []domain.TaskSort{{Field: domain.SortActionTime}}
+	sort.Slice(filtered, func(i, j int) bool {
+		return lessTask(filtered[i], filtered[j], sorts, false)
+	})
+
+	if len(filtered) > params.Limit {
+		filtered = filtered[:params.Limit]
+	}
+
+	return filtered, nil
+}
+
+// filterTasks returns copies of the tasks matching params and placed after cursor,
+// search rank & highlights are filled when params has a query
+func (instance *taskMemory) filterTasks(ctx context.Context, params *domain.TaskParams, cursor *domain.TaskCursor) []*domain.Task {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var terms []string
+	if params.Q != nil {
+		terms = searchTerms(*params.Q)
+	}
+
+	var filtered []*domain.Task
+	for _, task := range instance.tasks {
+		if !domain.OwnedBy(ctx, task.OwnerID) || !matchTaskParams(task, params) {
+			continue
+		}
+		if cursor != nil && !afterCursor(task, cursor) {
+			continue
+		}
+		if params.IsBlocked != nil && instance.isBlocked(task) != *params.IsBlocked {
+			continue
+		}
+		if params.Q != nil && !matchSearch(task, terms) {
+			continue
+		}
+
+		copied := copyTask(task)
+		if params.Q != nil {
+			copied.Rank = searchRank(copied, terms)
+			copied.Highlights = highlightTask(copied, terms)
+		}
+		filtered = append(filtered, copied)
+	}
+
+	return filtered
+}
+
+// lessTask applies the same ordering as taskPostgres.GetAllWithPaginate, ties are broken by
+// search rank when ranked and then by id
+func lessTask(a, b *domain.Task, sorts []domain.TaskSort, ranked bool) bool {
+	for _, sort := range sorts {
+		var cmp int
+
+		switch sort.Field {
+		case domain.SortActionTime:
+			cmp = compareTime(a.ActionTime, b.ActionTime)
+		case domain.SortCreatedAt:
+			cmp = compareTime(a.CreatedAt, b.CreatedAt)
+		case domain.SortUpdatedAt:
+			cmp = compareTime(a.UpdatedAt, b.UpdatedAt)
+		case domain.SortTitle:
+			cmp = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case domain.SortProgress:
+			cmp = compareFloat(a.Progress(), b.Progress())
+		case domain.SortUrgency:
+			cmp = compareUrgency(a, b)
+		}
+
+		if sort.Desc {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp < 0
+		}
+	}
+
+	if ranked && a.Rank != b.Rank {
+		return a.Rank > b.Rank
+	}
+
+	return a.ID < b.ID
+}
+
+// compareUrgency is negative when a is more urgent than b, same as sortUrgency
+func compareUrgency(a, b *domain.Task) int {
+	switch {
+	case a.Priority > b.Priority:
+		return -1
+	case a.Priority < b.Priority:
+		return 1
+	case a.DueAt == nil || b.DueAt == nil:
+		if a.DueAt == nil && b.DueAt == nil {
+			return 0
+		}
+		if a.DueAt == nil {
+			return 1
+		}
+		return -1
+	}
+
+	return compareTime(*a.DueAt, *b.DueAt)
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// afterCursor reports whether task comes after the cursor in (action_time, id) order
+func afterCursor(task *domain.Task, cursor *domain.TaskCursor) bool {
+	if task.ActionTime.Equal(cursor.ActionTime) {
+		return task.ID > cursor.ID
+	}
+
+	return task.ActionTime.After(cursor.ActionTime)
+}
@@ -0,0 +1,132 @@
+package taskrps
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/outboxrps"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"testing"
+	"time"
+)
+
+// testBase is the action time of the first seeded task, the others follow it by an hour
+var testBase = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+// seedTasks creates the tasks every repository is queried on, the last one is of another user
+func seedTasks(t *testing.T, repo ports.TaskRepository) {
+	t.Helper()
+
+	tasks := []*domain.Task{
+		{OwnerID: 1, Title: "Buy Milk", Priority: domain.PriorityHigh, IsFinished: true},
+		{OwnerID: 1, Title: "buy bread"},
+		{OwnerID: 1, Title: "Walk dog"},
+		{OwnerID: 1, Title: "Call mom", Priority: domain.PriorityHigh},
+		{OwnerID: 1, Title: "Shopping", Objective: []*domain.Objective{{ObjectiveName: "oat milk"}}},
+		{OwnerID: 2, Title: "buy milk"},
+	}
+
+	for i, task := range tasks {
+		task.ActionTime = testBase.Add(time.Duration(i) * time.Hour)
+		task.Version = 1
+		require.NoError(t, repo.Create(domain.WithOwner(context.Background(), task.OwnerID), task))
+	}
+}
+
+func titles(tasks []*domain.Task) []string {
+	var list []string
+	for _, task := range tasks {
+		list = append(list, task.Title)
+	}
+
+	return list
+}
+
+func queryTitles(t *testing.T, repo ports.TaskRepository, ctx context.Context, params domain.TaskParams) []string {
+	t.Helper()
+
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.Limit == 0 {
+		params.Limit = 10
+	}
+	params.Sort = append(params.Sort, domain.SortActionTime)
+
+	tasks, _, err := repo.GetAllWithPaginate(ctx, &params)
+	require.NoError(t, err)
+
+	return titles(tasks)
+}
+
+// testTaskQueries checks the filters and the pagination of repo, every repository gives the
+// same results as postgres
+func testTaskQueries(t *testing.T, repo ports.TaskRepository) {
+	seedTasks(t, repo)
+	ctx := domain.WithOwner(context.Background(), 1)
+
+	unix := func(at time.Time) *int {
+		value := int(at.Unix())
+		return &value
+	}
+	title := "MILK"
+	query := "milk"
+	finished := true
+
+	t.Run("filters", func(t *testing.T) {
+		assert.Equal(t, []string{"Buy Milk"}, queryTitles(t, repo, ctx, domain.TaskParams{Title: &title}))
+		assert.Equal(t, []string{"buy bread", "Walk dog"}, queryTitles(t, repo, ctx, domain.TaskParams{
+			ActionTimeStart: unix(testBase.Add(time.Hour)),
+			ActionTimeEnd:   unix(testBase.Add(2 * time.Hour)),
+		}))
+		assert.Equal(t, []string{"Buy Milk"}, queryTitles(t, repo, ctx, domain.TaskParams{IsFinished: &finished}))
+		assert.Equal(t, []string{"Buy Milk", "Call mom"}, queryTitles(t, repo, ctx, domain.TaskParams{Priority: []string{"high"}}))
+		assert.ElementsMatch(t, []string{"Buy Milk", "Shopping"}, queryTitles(t, repo, ctx, domain.TaskParams{Q: &query}))
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		params := &domain.TaskParams{Page: 2, Limit: 2, Sort: []string{domain.SortActionTime}}
+		tasks, total, err := repo.GetAllWithPaginate(ctx, params)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		assert.Equal(t, []string{"Walk dog", "Call mom"}, titles(tasks))
+
+		params.Page = 4
+		tasks, total, err = repo.GetAllWithPaginate(ctx, params)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		var (
+			all    []string
+			cursor *domain.TaskCursor
+		)
+
+		for page := 0; page < 5; page++ {
+			tasks, err := repo.GetAllWithCursor(ctx, &domain.TaskParams{Limit: 2}, cursor)
+			require.NoError(t, err)
+			if len(tasks) == 0 {
+				break
+			}
+
+			all = append(all, titles(tasks)...)
+			cursor = tasks[len(tasks)-1].ToTaskCursor()
+		}
+
+		assert.Equal(t, []string{"Buy Milk", "buy bread", "Walk dog", "Call mom", "Shopping"}, all)
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		// a query made without a user sees no task, the background jobs see the tasks of every user
+		assert.Empty(t, queryTitles(t, repo, context.Background(), domain.TaskParams{}))
+		assert.Len(t, queryTitles(t, repo, domain.WithSystem(context.Background()), domain.TaskParams{}), 6)
+		assert.Equal(t, []string{"buy milk"}, queryTitles(t, repo, domain.WithOwner(context.Background(), 2), domain.TaskParams{}))
+	})
+}
+
+func TestTaskMemoryQueries(t *testing.T) {
+	testTaskQueries(t, NewTaskMemory(outboxrps.NewOutboxMemory()))
+}
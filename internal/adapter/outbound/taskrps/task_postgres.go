@@ -3,40 +3,80 @@ package taskrps
 import (
 	"context"
 	"errors"
+	"github.com/todo-list/internal/adapter/outbound/scope"
 	"github.com/todo-list/internal/core/domain"
 	"github.com/todo-list/internal/core/ports"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strconv"
 	"time"
 )
 
 type taskPostgres struct {
 	postgres *gorm.DB
+	search   taskSearch
 }
 
 func NewTaskPostgres(postgres *gorm.DB) ports.TaskRepository {
 	return &taskPostgres{
 		postgres: postgres,
+		search:   postgresSearch{},
 	}
 }
 
 // Create is creating new task and objectives
 func (instance *taskPostgres) Create(ctx context.Context, task *domain.Task) error {
 	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
-		// save task
-		if err := tx.Debug().Save(&task).Error; err != nil {
+		return createTask(ctx, tx, task)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Transaction runs fn on a repository bound to a transaction, the transactions the methods of that
+// repository open are savepoints of it
+func (instance *taskPostgres) Transaction(ctx context.Context, fn func(repo ports.TaskRepository) error) error {
+	return instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		return fn(&taskPostgres{
+			postgres: tx,
+			search:   instance.search,
+		})
+	})
+}
+
+func createTask(ctx context.Context, tx *gorm.DB, task *domain.Task) error {
+	// save task
+	if err := tx.Debug().Save(&task).Error; err != nil {
+		return err
+	}
+
+	// save objectives
+	for _, obj := range task.Objective {
+		obj.TaskID = task.ID
+		if err := tx.Debug().Save(&obj).Error; err != nil {
 			return err
 		}
+	}
 
-		// save objectives
-		for _, obj := range task.Objective {
-			obj.TaskID = task.ID
-			if err := tx.Debug().Save(&obj).Error; err != nil {
-				return err
-			}
-		}
+	if err := saveTags(tx, task); err != nil {
+		return err
+	}
 
-		return nil
+	if err := saveAudit(ctx, tx, domain.AuditCreate, nil, task.ID); err != nil {
+		return err
+	}
+
+	return saveEvents(tx, task)
+}
+
+// Update is updating task and objectives, objectives are diffed against the existing rows
+// so the ids of kept objectives never change. A nil objective list leaves them untouched.
+// The task is only updated when its version wasn't changed since it was read.
+func (instance *taskPostgres) Update(ctx context.Context, task *domain.Task) error {
+	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		return updateTask(ctx, tx, task)
 	}); err != nil {
 		return err
 	}
@@ -44,30 +84,22 @@ func (instance *taskPostgres) Create(ctx context.Context, task *domain.Task) err
 	return nil
 }
 
-// Update is updating task and objectives
-func (instance *taskPostgres) Update(ctx context.Context, task *domain.Task) error {
+// SaveOccurrence is updating task like Update and creating next, the occurrence following it
+func (instance *taskPostgres) SaveOccurrence(ctx context.Context, task *domain.Task, next *domain.Task) error {
 	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
-		if len(task.Objective) > 0 {
-			// delete objectives
-			if err := tx.Debug().
-				Where("task_id = ?", strconv.Itoa(int(task.ID))).
-				Delete(&domain.Objective{}).Unscoped().Error; err != nil {
-				return err
-			}
+		if err := updateTask(ctx, tx, task); err != nil {
+			return err
+		}
 
-			// save new objectives
-			for _, obj := range task.Objective {
-				obj.TaskID = task.ID
-				if err := tx.Debug().Save(&obj).Error; err != nil {
-					return err
-				}
-			}
+		if err := createTask(ctx, tx, next); err != nil {
+			return err
 		}
 
-		// update task
-		if err := tx.Save(&task).Error; err != nil {
+		// the next occurrence is linked without a new version, task was just saved
+		if err := tx.Model(&domain.Task{}).Where("id = ?", task.ID).Update("next_id", next.ID).Error; err != nil {
 			return err
 		}
+		task.NextID = &next.ID
 
 		return nil
 	}); err != nil {
@@ -77,11 +109,114 @@ func (instance *taskPostgres) Update(ctx context.Context, task *domain.Task) err
 	return nil
 }
 
-// GetOneByID is getting task by id and its objectives
+func updateTask(ctx context.Context, tx *gorm.DB, task *domain.Task) error {
+	before, err := getStored(tx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	// update task
+	task.UpdatedAt = time.Now()
+	if err := bumpVersion(tx, task, map[string]interface{}{
+		"project_id":       task.ProjectID,
+		"parent_id":        task.ParentID,
+		"title":            task.Title,
+		"priority":         task.Priority,
+		"action_time":      task.ActionTime,
+		"due_at":           task.DueAt,
+		"is_finished":      task.IsFinished,
+		"recurrence":       task.Recurrence,
+		"recurrence_start": task.RecurrenceStart,
+		"has_next":         task.HasNext,
+		"updated_at":       task.UpdatedAt,
+	}); err != nil {
+		return err
+	}
+
+	if task.Objective != nil {
+		if err := saveObjectivesDiff(tx, task); err != nil {
+			return err
+		}
+	}
+
+	if err := saveTags(tx, task); err != nil {
+		return err
+	}
+
+	if err := saveAudit(ctx, tx, domain.AuditUpdate, before, task.ID); err != nil {
+		return err
+	}
+
+	return saveEvents(tx, task)
+}
+
+// saveObjectivesDiff inserts the objectives without id, updates the changed ones and deletes
+// the existing ones missing from task
+func saveObjectivesDiff(tx *gorm.DB, task *domain.Task) error {
+	var existing []*domain.Objective
+	if err := tx.Debug().Where("task_id = ?", strconv.Itoa(int(task.ID))).Find(&existing).Error; err != nil {
+		return err
+	}
+
+	stored := map[uint64]*domain.Objective{}
+	for _, obj := range existing {
+		stored[obj.ID] = obj
+	}
+
+	for _, obj := range task.Objective {
+		obj.TaskID = task.ID
+
+		old, ok := stored[obj.ID]
+		if !ok {
+			obj.ID = 0
+			if err := tx.Debug().Create(&obj).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		delete(stored, obj.ID)
+		if old.ObjectiveName == obj.ObjectiveName && old.IsFinished == obj.IsFinished {
+			continue
+		}
+
+		if err := tx.Debug().Model(&domain.Objective{}).
+			Where("id = ?", obj.ID).
+			Updates(map[string]interface{}{
+				"objective_name": obj.ObjectiveName,
+				"is_finished":    obj.IsFinished,
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	var removed []uint64
+	for id := range stored {
+		removed = append(removed, id)
+	}
+	if len(removed) > 0 {
+		if err := tx.Debug().Where("id IN ?", removed).Delete(&domain.Objective{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetOneByID is getting task by id and its objectives, trashed tasks are not found
 func (instance *taskPostgres) GetOneByID(ctx context.Context, id string) (*domain.Task, error) {
+	return instance.getOne(instance.postgres.Scopes(scope.Owner(ctx)).Where("id = ? AND deleted_at IS NULL", id))
+}
+
+// GetTrashedByID is getting a trashed task by id and its objectives
+func (instance *taskPostgres) GetTrashedByID(ctx context.Context, id string) (*domain.Task, error) {
+	return instance.getOne(instance.postgres.Scopes(scope.Owner(ctx)).Where("id = ? AND deleted_at IS NOT NULL", id))
+}
+
+func (instance *taskPostgres) getOne(q *gorm.DB) (*domain.Task, error) {
 	var task *domain.Task
 
-	if err := instance.postgres.Debug().Preload("Objective").Where("id = ?", id).First(&task).Error; err != nil {
+	if err := q.Debug().Preload("Objective").First(&task).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -89,22 +224,106 @@ func (instance *taskPostgres) GetOneByID(ctx context.Context, id string) (*domai
 		return nil, err
 	}
 
+	if err := loadAssociations(instance.postgres, task); err != nil {
+		return nil, err
+	}
+
 	return task, nil
 }
 
-func (instance *taskPostgres) Delete(ctx context.Context, id string) error {
+// Delete is moving task to the trash when its version wasn't changed since it was read,
+// its objectives are kept so it can be restored
+func (instance *taskPostgres) Delete(ctx context.Context, task *domain.Task) error {
+	now := time.Now()
+
+	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		before, err := getStored(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := bumpVersion(tx, task, map[string]interface{}{
+			"deleted_at": now,
+		}); err != nil {
+			return err
+		}
+
+		task.DeletedAt = &now
+
+		if err := saveAudit(ctx, tx, domain.AuditDelete, before, task.ID); err != nil {
+			return err
+		}
+
+		return saveEvents(tx, task)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Restore is moving task out of the trash when its version wasn't changed since it was read
+func (instance *taskPostgres) Restore(ctx context.Context, task *domain.Task) error {
+	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		before, err := getStored(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := bumpVersion(tx, task, map[string]interface{}{
+			"deleted_at": nil,
+		}); err != nil {
+			return err
+		}
+
+		task.DeletedAt = nil
+
+		if err := saveAudit(ctx, tx, domain.AuditRestore, before, task.ID); err != nil {
+			return err
+		}
+
+		return saveEvents(tx, task)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Purge is permanently deleting task and its objectives when its version wasn't changed since it was read
+func (instance *taskPostgres) Purge(ctx context.Context, task *domain.Task) error {
 	if err := instance.postgres.Transaction(func(tx *gorm.DB) error {
+		before, err := getStored(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		// lock task on its version
+		if err := bumpVersion(tx, task, map[string]interface{}{}); err != nil {
+			return err
+		}
+
 		// delete objective
-		if err := tx.Where("task_id = ?", id).Delete(&domain.Objective{}).Unscoped().Error; err != nil {
+		if err := tx.Where("task_id = ?", task.ID).Delete(&domain.Objective{}).Error; err != nil {
+			return err
+		}
+
+		// delete tags, the tags themselves are kept for the other tasks
+		if err := tx.Where("task_id = ?", task.ID).Delete(&domain.TaskTag{}).Error; err != nil {
+			return err
+		}
+
+		// delete dependencies, the tasks it blocked aren't blocked by it anymore
+		if err := tx.Where("task_id = ? OR blocker_id = ?", task.ID, task.ID).Delete(&domain.TaskDependency{}).Error; err != nil {
 			return err
 		}
 
 		// delete task
-		if err := tx.Where("id = ?", id).Delete(&domain.Task{}).Unscoped().Error; err != nil {
+		if err := tx.Where("id = ?", task.ID).Delete(&domain.Task{}).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return saveAudit(ctx, tx, domain.AuditPurge, before, task.ID)
 	}); err != nil {
 		return err
 	}
@@ -112,14 +331,480 @@ func (instance *taskPostgres) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// GetAllAuditLogWithPaginate is getting the audit log of a task, newest first. It is kept after
+// the task was purged.
+func (instance *taskPostgres) GetAllAuditLogWithPaginate(ctx context.Context, id string, params *domain.AuditLogParams) ([]*domain.AuditLog, int64, error) {
+	var (
+		audits []*domain.AuditLog
+		total  int64
+	)
+
+	q := instance.postgres.Debug().Model(&domain.AuditLog{}).Scopes(scope.Owner(ctx)).Where("task_id = ?", id)
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := q.Order("id DESC").Limit(params.Limit).Offset(params.Limit * (params.Page - 1)).Find(&audits).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return audits, total, nil
+}
+
 func (instance *taskPostgres) GetAllWithPaginate(ctx context.Context, params *domain.TaskParams) ([]*domain.Task, int64, error) {
 	var (
 		tasks []*domain.Task
 		total int64
 	)
 
-	q := instance.postgres.Preload("Objective").Debug()
+	q := instance.filterTasks(instance.postgres.Preload("Objective").Debug().Scopes(scope.Owner(ctx)), params)
+
+	if err := q.Model(&domain.Task{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.Q != nil {
+		q = instance.search.rank(q, *params.Q)
+	}
+
+	if err := sortTasks(q, params).Limit(params.Limit).Offset(params.Limit * (params.Page - 1)).Find(&tasks).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, nil
+		}
+
+		return nil, 0, err
+	}
+
+	if err := loadAssociations(instance.postgres, tasks...); err != nil {
+		return nil, 0, err
+	}
+
+	if params.Q != nil {
+		if err := instance.search.highlight(instance.postgres.Debug(), tasks, *params.Q); err != nil {
+			return nil, 0, err
+		}
+	}
 
+	return tasks, total, nil
+}
+
+// GetAllWithCursor is getting tasks ordered by action time after the cursor, without counting the total
+func (instance *taskPostgres) GetAllWithCursor(ctx context.Context, params *domain.TaskParams, cursor *domain.TaskCursor) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+
+	q := instance.filterTasks(instance.postgres.Preload("Objective").Debug().Scopes(scope.Owner(ctx)), params)
+
+	if cursor != nil {
+		q = q.Where("(action_time > ? OR (action_time = ? AND id > ?))", cursor.ActionTime, cursor.ActionTime, cursor.ID)
+	}
+
+	if params.Q != nil {
+		q = instance.search.rank(q, *params.Q)
+	}
+
+	if err := q.Order("action_time, id").Limit(params.Limit).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	if err := loadAssociations(instance.postgres, tasks...); err != nil {
+		return nil, err
+	}
+
+	if params.Q != nil {
+		if err := instance.search.highlight(instance.postgres.Debug(), tasks, *params.Q); err != nil {
+			return nil, err
+		}
+	}
+
+	return tasks, nil
+}
+
+// CountByProject is counting the open, finished and trashed tasks of each project, the projects
+// without tasks are missing from the map
+func (instance *taskPostgres) CountByProject(ctx context.Context, projectIDs []uint64) (map[uint64]*domain.ProjectCount, error) {
+	var counts []*domain.ProjectCount
+
+	if len(projectIDs) == 0 {
+		return map[uint64]*domain.ProjectCount{}, nil
+	}
+
+	if err := instance.postgres.Debug().Model(&domain.Task{}).Scopes(scope.Owner(ctx)).
+		Select(`project_id,
+			SUM(CASE WHEN deleted_at IS NULL AND NOT is_finished THEN 1 ELSE 0 END) AS open,
+			SUM(CASE WHEN deleted_at IS NULL AND is_finished THEN 1 ELSE 0 END) AS finished,
+			SUM(CASE WHEN deleted_at IS NOT NULL THEN 1 ELSE 0 END) AS trashed`).
+		Where("project_id IN ?", projectIDs).
+		Group("project_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	byProject := map[uint64]*domain.ProjectCount{}
+	for _, count := range counts {
+		byProject[count.ProjectID] = count
+	}
+
+	return byProject, nil
+}
+
+// GetAllByParent is getting the subtasks of the tasks with the given ids, trashed ones included
+func (instance *taskPostgres) GetAllByParent(ctx context.Context, parentIDs []uint64) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+
+	if err := instance.postgres.Debug().Preload("Objective").Scopes(scope.Owner(ctx)).
+		Where("parent_id IN ?", parentIDs).
+		Order("id").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	if err := loadAssociations(instance.postgres, tasks...); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// GetAllDueRecurring is getting the recurring tasks whose action time passed and whose next
+// occurrence wasn't created yet
+func (instance *taskPostgres) GetAllDueRecurring(ctx context.Context, now time.Time, limit int) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+
+	if err := instance.postgres.Debug().Preload("Objective").
+		Where("recurrence IS NOT NULL AND has_next = ? AND deleted_at IS NULL AND action_time <= ?", false, now.UTC()).
+		Order("action_time, id").
+		Limit(limit).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	if err := loadAssociations(instance.postgres, tasks...); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// GetAllDueReminders is getting the unfinished tasks whose action time is within offset from now
+// and whose reminder for this offset & action time wasn't claimed yet
+func (instance *taskPostgres) GetAllDueReminders(ctx context.Context, now time.Time, offset time.Duration, limit int) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+
+	if err := instance.postgres.Debug().
+		Where("deleted_at IS NULL AND is_finished = ? AND action_time > ? AND action_time <= ?", false, now.UTC(), now.Add(offset).UTC()).
+		Where(`NOT EXISTS (SELECT 1 FROM reminders WHERE reminders.task_id = tasks.id
+			AND reminders.offset_seconds = ? AND reminders.action_time = tasks.action_time)`, int64(offset/time.Second)).
+		Order("action_time, id").
+		Limit(limit).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// CreateReminder is claiming a reminder, it fails with domain.ErrReminderExists when it was already claimed
+func (instance *taskPostgres) CreateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	result := instance.postgres.Debug().
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Omit(clause.Associations).
+		Create(reminder)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReminderExists
+	}
+
+	return nil
+}
+
+// GetAllRetryReminders is getting the reminders not sent yet whose next attempt time came, with their
+// task when it wasn't purged
+func (instance *taskPostgres) GetAllRetryReminders(ctx context.Context, now time.Time, limit int) ([]*domain.Reminder, error) {
+	var reminders []*domain.Reminder
+
+	if err := instance.postgres.Debug().
+		Preload("Task").
+		Where("status <> ? AND next_attempt_at <= ?", domain.ReminderSent, now.UTC()).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+// ClaimReminder is saving the attempt of a reminder not sent yet, it fails with domain.ErrReminderExists
+// when it was sent or claimed again by another worker
+func (instance *taskPostgres) ClaimReminder(ctx context.Context, reminder *domain.Reminder, now time.Time) error {
+	result := instance.postgres.Debug().Model(&domain.Reminder{}).
+		Where("id = ? AND status <> ? AND next_attempt_at <= ?", reminder.ID, domain.ReminderSent, now.UTC()).
+		Updates(map[string]interface{}{
+			"status":          reminder.Status,
+			"attempts":        reminder.Attempts,
+			"next_attempt_at": reminder.NextAttemptAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReminderExists
+	}
+
+	return nil
+}
+
+// UpdateReminder is saving the delivery state of a reminder
+func (instance *taskPostgres) UpdateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	return instance.postgres.Debug().Model(&domain.Reminder{}).
+		Where("id = ?", reminder.ID).
+		Updates(map[string]interface{}{
+			"status":          reminder.Status,
+			"error":           reminder.Error,
+			"attempts":        reminder.Attempts,
+			"next_attempt_at": reminder.NextAttemptAt,
+			"sent_at":         reminder.SentAt,
+		}).Error
+}
+
+// CreateObjective is creating one objective and saving the finished state of its task
+func (instance *taskPostgres) CreateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		before, err := getStored(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		objective.TaskID = task.ID
+		if err := tx.Debug().Create(&objective).Error; err != nil {
+			return err
+		}
+
+		if err := saveTaskState(tx, task); err != nil {
+			return err
+		}
+
+		return saveAudit(ctx, tx, domain.AuditUpdate, before, task.ID)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateObjective is updating one objective and saving the finished state of its task
+func (instance *taskPostgres) UpdateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		before, err := getStored(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Debug().Model(&domain.Objective{}).
+			Where("id = ? AND task_id = ?", objective.ID, task.ID).
+			Updates(map[string]interface{}{
+				"objective_name": objective.ObjectiveName,
+				"is_finished":    objective.IsFinished,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := saveTaskState(tx, task); err != nil {
+			return err
+		}
+
+		return saveAudit(ctx, tx, domain.AuditUpdate, before, task.ID)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteObjective is deleting one objective and saving the finished state of its task
+func (instance *taskPostgres) DeleteObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error {
+	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		before, err := getStored(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Debug().
+			Where("id = ? AND task_id = ?", objective.ID, task.ID).
+			Delete(&domain.Objective{}).Error; err != nil {
+			return err
+		}
+
+		if err := saveTaskState(tx, task); err != nil {
+			return err
+		}
+
+		return saveAudit(ctx, tx, domain.AuditUpdate, before, task.ID)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// saveTaskState saves the finished state of task after one of its objectives changed
+func saveTaskState(tx *gorm.DB, task *domain.Task) error {
+	task.UpdatedAt = time.Now()
+
+	if err := bumpVersion(tx, task, map[string]interface{}{
+		"is_finished": task.IsFinished,
+		"updated_at":  task.UpdatedAt,
+	}); err != nil {
+		return err
+	}
+
+	return saveEvents(tx, task)
+}
+
+// loadAssociations fills the tags & blockers of tasks
+func loadAssociations(tx *gorm.DB, tasks ...*domain.Task) error {
+	if err := loadTags(tx, tasks...); err != nil {
+		return err
+	}
+
+	return loadBlockers(tx, tasks...)
+}
+
+// getStored reads the stored task & objectives in tx, trashed tasks included
+func getStored(tx *gorm.DB, id uint64) (*domain.Task, error) {
+	var task *domain.Task
+
+	if err := tx.Debug().Preload("Objective").Where("id = ?", id).First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if err := loadAssociations(tx, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// saveAudit writes the audit log of a change of the task in the transaction making it, before is the
+// task read in the transaction before the change and the after snapshot is read once it was made
+func saveAudit(ctx context.Context, tx *gorm.DB, action string, before *domain.Task, id uint64) error {
+	after, err := getStored(tx, id)
+	if err != nil {
+		return err
+	}
+
+	audit, err := domain.NewAuditLog(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Debug().Create(&audit).Error
+}
+
+// saveEvents writes the events recorded on task to the outbox in the transaction saving it,
+// so an event is published if and only if the change it describes was committed
+func saveEvents(tx *gorm.DB, task *domain.Task) error {
+	events, err := task.PullEvents()
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	return tx.Debug().Create(&events).Error
+}
+
+// bumpVersion updates the columns of task and increments its version, only when the stored
+// version is still the one task was read with
+func bumpVersion(tx *gorm.DB, task *domain.Task, columns map[string]interface{}) error {
+	columns["version"] = gorm.Expr("version + 1")
+
+	result := tx.Debug().Model(&domain.Task{}).
+		Where("id = ? AND version = ?", task.ID, task.Version).
+		UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTaskVersionConflict
+	}
+
+	task.Version++
+
+	return nil
+}
+
+// taskSortColumns is the whitelist of sortable columns, progress is the ratio of finished objectives
+var taskSortColumns = map[string]string{
+	domain.SortActionTime: "action_time",
+	domain.SortCreatedAt:  "created_at",
+	domain.SortUpdatedAt:  "updated_at",
+	domain.SortTitle:      "LOWER(title)",
+	domain.SortProgress: `(SELECT CASE WHEN COUNT(*) = 0 THEN CASE WHEN tasks.is_finished THEN 1.0 ELSE 0.0 END
+		ELSE 1.0 * SUM(CASE WHEN objectives.is_finished THEN 1 ELSE 0 END) / COUNT(*) END
+		FROM objectives WHERE objectives.task_id = tasks.id)`,
+}
+
+// sortTasks orders by the requested sorts then by relevance when searching, always ending
+// with id so pages are deterministic
+func sortTasks(q *gorm.DB, params *domain.TaskParams) *gorm.DB {
+	for _, sort := range params.GetSorts() {
+		if sort.Field == domain.SortUrgency {
+			q = sortUrgency(q, sort.Desc)
+			continue
+		}
+
+		column, ok := taskSortColumns[sort.Field]
+		if !ok {
+			continue
+		}
+
+		if sort.Desc {
+			q = q.Order(column + " DESC")
+		} else {
+			q = q.Order(column + " ASC")
+		}
+	}
+
+	if params.Q != nil {
+		q = q.Order("search_rank DESC")
+	}
+
+	return q.Order("id ASC")
+}
+
+// sortUrgency orders by priority then due date, the tasks without due date are the least urgent
+// of their priority. Desc lists the least urgent first.
+func sortUrgency(q *gorm.DB, desc bool) *gorm.DB {
+	if desc {
+		return q.Order("priority ASC").Order("CASE WHEN due_at IS NULL THEN 0 ELSE 1 END").Order("due_at DESC")
+	}
+
+	return q.Order("priority DESC").Order("CASE WHEN due_at IS NULL THEN 1 ELSE 0 END").Order("due_at ASC")
+}
+
+func (instance *taskPostgres) filterTasks(q *gorm.DB, params *domain.TaskParams) *gorm.DB {
+	if params.Trashed {
+		q = q.Where("deleted_at IS NOT NULL")
+	} else {
+		q = q.Where("deleted_at IS NULL")
+	}
+	if params.Q != nil {
+		q = instance.search.filter(q, *params.Q)
+	}
 	if params.Title != nil {
 		q = q.Where(`LOWER(title) LIKE LOWER(?)`, "%"+*params.Title+"%")
 	}
@@ -132,18 +817,24 @@ func (instance *taskPostgres) GetAllWithPaginate(ctx context.Context, params *do
 	if params.IsFinished != nil {
 		q = q.Where("is_finished = ?", params.IsFinished)
 	}
-
-	if err := q.Model(&domain.Task{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	if params.ProjectID != nil {
+		q = q.Where("project_id = ?", *params.ProjectID)
 	}
-
-	if err := q.Limit(params.Limit).Offset(params.Limit * (params.Page - 1)).Find(&tasks).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, 0, nil
-		}
-
-		return nil, 0, err
+	if len(params.Priority) > 0 {
+		q = q.Where("priority IN ?", domain.ParsePriorities(params.Priority))
+	}
+	if params.DueTimeStart != nil {
+		q = q.Where("due_at >= ?", time.Unix(int64(*params.DueTimeStart), 0).UTC())
+	}
+	if params.DueTimeEnd != nil {
+		q = q.Where("due_at <= ?", time.Unix(int64(*params.DueTimeEnd), 0).UTC())
+	}
+	if tags := domain.NormalizeTags(params.Tags); len(tags) > 0 {
+		q = filterTags(q, tags, params.TagMode)
+	}
+	if params.IsBlocked != nil {
+		q = filterBlocked(q, *params.IsBlocked)
 	}
 
-	return tasks, total, nil
+	return q
 }
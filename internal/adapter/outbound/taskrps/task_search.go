@@ -0,0 +1,198 @@
+package taskrps
+
+import (
+	"fmt"
+	"github.com/todo-list/internal/core/domain"
+	"gorm.io/gorm"
+	"regexp"
+	"strings"
+)
+
+const (
+	searchTitleWeight     = 1.0
+	searchObjectiveWeight = 0.4
+)
+
+// taskSearch builds the full text search parts of a task query for one sql dialect
+type taskSearch interface {
+	// filter keeps the tasks whose title or objectives match query
+	filter(q *gorm.DB, query string) *gorm.DB
+	// rank selects the relevance of every task as search_rank
+	rank(q *gorm.DB, query string) *gorm.DB
+	// highlight fills the highlights of tasks found by query
+	highlight(db *gorm.DB, tasks []*domain.Task, query string) error
+}
+
+// postgresSearch uses the search_vector column kept up to date by triggers
+type postgresSearch struct{}
+
+func (postgresSearch) filter(q *gorm.DB, query string) *gorm.DB {
+	return q.Where("search_vector @@ websearch_to_tsquery('english', ?)", query)
+}
+
+func (postgresSearch) rank(q *gorm.DB, query string) *gorm.DB {
+	return q.Select("tasks.*, ts_rank(search_vector, websearch_to_tsquery('english', ?)) AS search_rank", query)
+}
+
+func (postgresSearch) highlight(db *gorm.DB, tasks []*domain.Task, query string) error {
+	var (
+		ids        []uint64
+		highlights []struct {
+			TaskID   uint64
+			Fragment string
+		}
+	)
+
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	if err := db.Raw(`WITH search AS (SELECT websearch_to_tsquery('english', ?) AS query)
+		SELECT task_id, fragment FROM (
+			SELECT tasks.id AS task_id, 0 AS position,
+				ts_headline('english', tasks.title, search.query, 'HighlightAll=true') AS fragment
+			FROM tasks, search
+			WHERE tasks.id IN ? AND to_tsvector('english', tasks.title) @@ search.query
+			UNION ALL
+			SELECT objectives.task_id, objectives.id AS position,
+				ts_headline('english', objectives.objective_name, search.query, 'HighlightAll=true') AS fragment
+			FROM objectives, search
+			WHERE objectives.task_id IN ? AND to_tsvector('english', objectives.objective_name) @@ search.query
+		) AS highlights
+		ORDER BY task_id, position`, query, ids, ids).Scan(&highlights).Error; err != nil {
+		return err
+	}
+
+	byID := map[uint64]*domain.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	for _, highlight := range highlights {
+		if task, ok := byID[highlight.TaskID]; ok {
+			task.Highlights = append(task.Highlights, highlight.Fragment)
+		}
+	}
+
+	return nil
+}
+
+// likeSearch is the fallback for databases without full text search, every word of the query
+// has to be found in the title or in one of the objectives
+type likeSearch struct{}
+
+const likeSearchObjective = "EXISTS (SELECT 1 FROM objectives WHERE objectives.task_id = tasks.id AND LOWER(objectives.objective_name) LIKE ?)"
+
+func (likeSearch) filter(q *gorm.DB, query string) *gorm.DB {
+	for _, term := range searchTerms(query) {
+		pattern := "%" + term + "%"
+		q = q.Where("(LOWER(title) LIKE ? OR "+likeSearchObjective+")", pattern, pattern)
+	}
+
+	return q
+}
+
+func (likeSearch) rank(q *gorm.DB, query string) *gorm.DB {
+	var (
+		parts []string
+		args  []interface{}
+	)
+
+	for _, term := range searchTerms(query) {
+		pattern := "%" + term + "%"
+		parts = append(parts,
+			fmt.Sprintf("CASE WHEN LOWER(title) LIKE ? THEN %.1f ELSE 0.0 END", searchTitleWeight),
+			fmt.Sprintf("CASE WHEN %s THEN %.1f ELSE 0.0 END", likeSearchObjective, searchObjectiveWeight))
+		args = append(args, pattern, pattern)
+	}
+	if len(parts) == 0 {
+		return q.Select("tasks.*, 0.0 AS search_rank")
+	}
+
+	return q.Select("tasks.*, ("+strings.Join(parts, " + ")+") AS search_rank", args...)
+}
+
+func (likeSearch) highlight(db *gorm.DB, tasks []*domain.Task, query string) error {
+	terms := searchTerms(query)
+	for _, task := range tasks {
+		task.Highlights = highlightTask(task, terms)
+	}
+
+	return nil
+}
+
+// searchTerms splits query into lower cased words
+func searchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// matchSearch reports whether every term is found in the title or the objectives of task
+func matchSearch(task *domain.Task, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(strings.ToLower(task.Title), term) && !matchObjectives(task, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// searchRank weights the terms found in the title above the ones found in objectives, same as likeSearch.rank
+func searchRank(task *domain.Task, terms []string) float64 {
+	var rank float64
+
+	for _, term := range terms {
+		if strings.Contains(strings.ToLower(task.Title), term) {
+			rank += searchTitleWeight
+		}
+		if matchObjectives(task, term) {
+			rank += searchObjectiveWeight
+		}
+	}
+
+	return rank
+}
+
+func matchObjectives(task *domain.Task, term string) bool {
+	for _, objective := range task.Objective {
+		if strings.Contains(strings.ToLower(objective.ObjectiveName), term) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// highlightTask wraps the terms found in the title and objectives with <b></b>, the same
+// markup ts_headline uses
+func highlightTask(task *domain.Task, terms []string) []string {
+	var (
+		highlights []string
+		quoted     []string
+	)
+
+	if len(terms) == 0 {
+		return nil
+	}
+
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	pattern := regexp.MustCompile("(?i)(" + strings.Join(quoted, "|") + ")")
+
+	texts := []string{task.Title}
+	for _, objective := range task.Objective {
+		texts = append(texts, objective.ObjectiveName)
+	}
+
+	for _, text := range texts {
+		if pattern.MatchString(text) {
+			highlights = append(highlights, pattern.ReplaceAllString(text, "<b>$1</b>"))
+		}
+	}
+
+	return highlights
+}
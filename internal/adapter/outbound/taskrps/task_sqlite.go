@@ -0,0 +1,36 @@
+package taskrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/ports"
+	"gorm.io/gorm"
+)
+
+// taskSQLite shares the gorm queries of taskPostgres, they are written in portable sql
+// so filters and pagination behave the same on both databases. Dialect specific queries
+// are overridden here.
+type taskSQLite struct {
+	*taskPostgres
+}
+
+func NewTaskSQLite(sqlite *gorm.DB) ports.TaskRepository {
+	return &taskSQLite{
+		taskPostgres: &taskPostgres{
+			postgres: sqlite,
+			search:   likeSearch{},
+		},
+	}
+}
+
+// Transaction keeps the sqlite queries in fn
+func (instance *taskSQLite) Transaction(ctx context.Context, fn func(repo ports.TaskRepository) error) error {
+	return instance.taskPostgres.Transaction(ctx, func(repo ports.TaskRepository) error {
+		return fn(&taskSQLite{taskPostgres: repo.(*taskPostgres)})
+	})
+}
+
+// LockDependencies starts the write of the transaction, sqlite has a single writer so the other
+// transactions wait for this one to be over
+func (instance *taskSQLite) LockDependencies(ctx context.Context) error {
+	return instance.postgres.Debug().Exec("UPDATE task_dependencies SET task_id = task_id WHERE 1 = 0").Error
+}
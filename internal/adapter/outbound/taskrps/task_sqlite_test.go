@@ -0,0 +1,18 @@
+package taskrps
+
+import (
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	sqlitePkg "github.com/todo-list/pkg/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func TestTaskSQLiteQueries(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, sqlitePkg.Migrate(db, filepath.Join("..", "..", "..", "..", "cmd", "migration")))
+
+	testTaskQueries(t, NewTaskSQLite(db))
+}
@@ -0,0 +1,128 @@
+package taskrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/adapter/outbound/scope"
+	"github.com/todo-list/internal/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// taskTagRow is a tag of a task as read from the join table
+type taskTagRow struct {
+	TaskID uint64
+	Name   string
+}
+
+// loadTags fills the tags of tasks in one query, the tasks without tags get an empty list
+func loadTags(tx *gorm.DB, tasks ...*domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := map[uint64]*domain.Task{}
+	var ids []uint64
+	for _, task := range tasks {
+		task.Tags = []string{}
+		byID[task.ID] = task
+		ids = append(ids, task.ID)
+	}
+
+	var rows []*taskTagRow
+	if err := tx.Debug().Table("task_tags").
+		Select("task_tags.task_id, tags.name").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("task_tags.task_id IN ?", ids).
+		Order("tags.name").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if task, ok := byID[row.TaskID]; ok {
+			task.Tags = append(task.Tags, row.Name)
+		}
+	}
+
+	return nil
+}
+
+// saveTags replaces the tags of task, the missing tags of its owner are created. A nil list
+// leaves them untouched.
+func saveTags(tx *gorm.DB, task *domain.Task) error {
+	if task.Tags == nil {
+		return nil
+	}
+
+	if err := tx.Debug().Where("task_id = ?", task.ID).Delete(&domain.TaskTag{}).Error; err != nil {
+		return err
+	}
+
+	if len(task.Tags) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var tags []*domain.Tag
+	for _, name := range task.Tags {
+		tags = append(tags, &domain.Tag{
+			OwnerID:   task.OwnerID,
+			Name:      name,
+			CreatedAt: now,
+		})
+	}
+
+	// the tags already created are skipped by the unique index of (owner_id, name)
+	if err := tx.Debug().Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return err
+	}
+
+	var tagIDs []uint64
+	if err := tx.Debug().Model(&domain.Tag{}).
+		Where("owner_id = ? AND name IN ?", task.OwnerID, task.Tags).
+		Pluck("id", &tagIDs).Error; err != nil {
+		return err
+	}
+
+	var taskTags []*domain.TaskTag
+	for _, tagID := range tagIDs {
+		taskTags = append(taskTags, &domain.TaskTag{
+			TaskID: task.ID,
+			TagID:  tagID,
+		})
+	}
+
+	return tx.Debug().Create(&taskTags).Error
+}
+
+// filterTags keeps the tasks having any or all of tags
+func filterTags(q *gorm.DB, tags []string, mode string) *gorm.DB {
+	sub := q.Session(&gorm.Session{NewDB: true}).Table("task_tags").
+		Select("task_tags.task_id").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("tags.name IN ?", tags)
+
+	if mode == domain.TagModeAll {
+		sub = sub.Group("task_tags.task_id").Having("COUNT(DISTINCT tags.name) = ?", len(tags))
+	}
+
+	return q.Where("tasks.id IN (?)", sub)
+}
+
+// GetAllTags is getting the tags with the number of the tasks labelled with each, the most used first
+func (instance *taskPostgres) GetAllTags(ctx context.Context) ([]*domain.TagCount, error) {
+	var counts []*domain.TagCount
+
+	q := instance.postgres.Debug().Table("tags").
+		Select("tags.name, COUNT(tasks.id) AS usage_count").
+		Joins("LEFT JOIN task_tags ON task_tags.tag_id = tags.id").
+		Joins("LEFT JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL").
+		Scopes(scope.OwnerColumn(ctx, "tags.owner_id"))
+
+	if err := q.Group("tags.id, tags.name").Order("usage_count DESC, tags.name").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
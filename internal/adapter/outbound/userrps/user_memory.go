@@ -0,0 +1,73 @@
+package userrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"sync"
+	"time"
+)
+
+type userMemory struct {
+	mu      sync.RWMutex
+	userSeq uint64
+	users   map[uint64]*domain.User
+}
+
+func NewUserMemory() ports.UserRepository {
+	return &userMemory{
+		users: map[uint64]*domain.User{},
+	}
+}
+
+// Create returns domain.ErrEmailTaken when a user with the same email exists, same as the
+// unique index of the users table
+func (instance *userMemory) Create(ctx context.Context, user *domain.User) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	for _, stored := range instance.users {
+		if stored.Email == user.Email {
+			return domain.ErrEmailTaken
+		}
+	}
+
+	now := time.Now()
+
+	instance.userSeq++
+	user.ID = instance.userSeq
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	copied := *user
+	instance.users[user.ID] = &copied
+
+	return nil
+}
+
+func (instance *userMemory) GetOneByID(ctx context.Context, id uint64) (*domain.User, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	user, ok := instance.users[id]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+func (instance *userMemory) GetOneByEmail(ctx context.Context, email string) (*domain.User, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	for _, user := range instance.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,81 @@
+package userrps
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// unownedTables are the tables whose rows were written before the users existed, the migration that
+// added the users left them owned by no user (owner_id 0)
+var unownedTables = []string{"tasks", "webhooks", "audit_log", "outbox_events"}
+
+type userPostgres struct {
+	postgres *gorm.DB
+}
+
+func NewUserPostgres(postgres *gorm.DB) ports.UserRepository {
+	return &userPostgres{
+		postgres: postgres,
+	}
+}
+
+// Create returns domain.ErrEmailTaken when a user with the same email exists. The first user
+// registered claims the rows written before the users existed, so they aren't left to nobody.
+func (instance *userPostgres) Create(ctx context.Context, user *domain.User) error {
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	return instance.postgres.Transaction(func(tx *gorm.DB) error {
+		result := tx.Debug().Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrEmailTaken
+		}
+
+		var previous int64
+		if err := tx.Debug().Model(&domain.User{}).Where("id < ?", user.ID).Count(&previous).Error; err != nil {
+			return err
+		}
+		if previous > 0 {
+			return nil
+		}
+
+		for _, table := range unownedTables {
+			if err := tx.Debug().Table(table).Where("owner_id = 0").Update("owner_id", user.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (instance *userPostgres) GetOneByID(ctx context.Context, id uint64) (*domain.User, error) {
+	return instance.getOne(instance.postgres.Where("id = ?", id))
+}
+
+func (instance *userPostgres) GetOneByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return instance.getOne(instance.postgres.Where("email = ?", email))
+}
+
+func (instance *userPostgres) getOne(q *gorm.DB) (*domain.User, error) {
+	var user *domain.User
+
+	if err := q.Debug().First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,56 @@
+package userrps
+
+import (
+	"context"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/taskrps"
+	"github.com/todo-list/internal/core/domain"
+	sqlitePkg "github.com/todo-list/pkg/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func countOwnedBy(t *testing.T, db *gorm.DB, table string, owner uint64) int64 {
+	t.Helper()
+
+	var count int64
+	require.NoError(t, db.Table(table).Where("owner_id = ?", owner).Count(&count).Error)
+
+	return count
+}
+
+func TestFirstUserClaimsUnownedRows(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, sqlitePkg.Migrate(db, filepath.Join("..", "..", "..", "..", "cmd", "migration")))
+
+	ctx := context.Background()
+	userRepo := NewUserPostgres(db)
+
+	// a task written before the users existed
+	task := &domain.Task{Title: "legacy", ActionTime: time.Now(), Version: 1}
+	require.NoError(t, taskrps.NewTaskSQLite(db).Create(ctx, task))
+	require.NoError(t, db.Exec("INSERT INTO webhooks (url, secret, events, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		"http://localhost/hook", "secret", "task.created", time.Now(), time.Now()).Error)
+
+	first := &domain.User{Email: "first@example.com", PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, first))
+
+	assert.Equal(t, int64(1), countOwnedBy(t, db, "tasks", first.ID))
+	assert.Equal(t, int64(1), countOwnedBy(t, db, "webhooks", first.ID))
+	assert.Zero(t, countOwnedBy(t, db, "tasks", 0))
+	assert.Zero(t, countOwnedBy(t, db, "outbox_events", 0))
+
+	// the rows are claimed once, the users registered later start without any
+	require.NoError(t, db.Exec("UPDATE tasks SET owner_id = 0").Error)
+
+	second := &domain.User{Email: "second@example.com", PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, second))
+	assert.Zero(t, countOwnedBy(t, db, "tasks", second.ID))
+
+	assert.ErrorIs(t, userRepo.Create(ctx, &domain.User{Email: "first@example.com", PasswordHash: "hash"}), domain.ErrEmailTaken)
+}
@@ -0,0 +1,58 @@
+package webhookhttp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/core/domain"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendSignsPayload(t *testing.T) {
+	payload := `{"Type":"task.created","Task":{"Task_ID":1}}`
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := &domain.Webhook{URL: server.URL, Secret: "webhook-secret"}
+	delivery := &domain.WebhookDelivery{ID: 7, Event: domain.EventTaskCreated, Payload: payload}
+
+	status, err := NewHTTPSender(time.Second).Send(context.Background(), webhook, delivery)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+
+	// the receiver checks the signature with the secret it was given
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	mac.Write(body)
+
+	require.NotNil(t, received)
+	assert.Equal(t, payload, string(body))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), received.Header.Get(HeaderSignature))
+	assert.Equal(t, domain.EventTaskCreated, received.Header.Get(HeaderEvent))
+	assert.Equal(t, "7", received.Header.Get(HeaderDelivery))
+}
+
+func TestSendTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	status, err := NewHTTPSender(50*time.Millisecond).Send(context.Background(), &domain.Webhook{URL: server.URL},
+		&domain.WebhookDelivery{Event: domain.EventTaskCreated, Payload: "{}"})
+	assert.Error(t, err)
+	assert.Zero(t, status)
+}
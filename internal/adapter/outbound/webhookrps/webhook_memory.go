@@ -0,0 +1,236 @@
+package webhookrps
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type webhookMemory struct {
+	mu          sync.RWMutex
+	webhookSeq  uint64
+	deliverySeq uint64
+	webhooks    map[uint64]*domain.Webhook
+	deliveries  map[uint64]*domain.WebhookDelivery
+}
+
+func NewWebhookMemory() ports.WebhookRepository {
+	return &webhookMemory{
+		webhooks:   map[uint64]*domain.Webhook{},
+		deliveries: map[uint64]*domain.WebhookDelivery{},
+	}
+}
+
+func (instance *webhookMemory) Create(ctx context.Context, webhook *domain.Webhook) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	now := time.Now()
+
+	instance.webhookSeq++
+	webhook.ID = instance.webhookSeq
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	copied := *webhook
+	instance.webhooks[webhook.ID] = &copied
+
+	return nil
+}
+
+func (instance *webhookMemory) Update(ctx context.Context, webhook *domain.Webhook) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.webhooks[webhook.ID]
+	if !ok {
+		return nil
+	}
+
+	webhook.UpdatedAt = time.Now()
+	stored.URL = webhook.URL
+	stored.Events = webhook.Events
+	stored.IsActive = webhook.IsActive
+	stored.UpdatedAt = webhook.UpdatedAt
+
+	return nil
+}
+
+// Delete is deleting webhook and its delivery log
+func (instance *webhookMemory) Delete(ctx context.Context, webhook *domain.Webhook) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	delete(instance.webhooks, webhook.ID)
+	for id, delivery := range instance.deliveries {
+		if delivery.WebhookID == webhook.ID {
+			delete(instance.deliveries, id)
+		}
+	}
+
+	return nil
+}
+
+func (instance *webhookMemory) GetOneByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	webhookID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	webhook, ok := instance.webhooks[webhookID]
+	if !ok || !domain.OwnedBy(ctx, webhook.OwnerID) {
+		return nil, nil
+	}
+
+	copied := *webhook
+	return &copied, nil
+}
+
+func (instance *webhookMemory) GetAll(ctx context.Context) ([]*domain.Webhook, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var webhooks []*domain.Webhook
+	for _, webhook := range instance.webhooks {
+		if !domain.OwnedBy(ctx, webhook.OwnerID) {
+			continue
+		}
+		copied := *webhook
+		webhooks = append(webhooks, &copied)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+// CreateDeliveries skips the deliveries of an event already queued for their webhook
+func (instance *webhookMemory) CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	queued := map[[2]uint64]bool{}
+	for _, delivery := range instance.deliveries {
+		queued[[2]uint64{delivery.WebhookID, delivery.EventID}] = true
+	}
+
+	for _, delivery := range deliveries {
+		if queued[[2]uint64{delivery.WebhookID, delivery.EventID}] {
+			continue
+		}
+
+		instance.deliverySeq++
+		delivery.ID = instance.deliverySeq
+
+		copied := *delivery
+		copied.Webhook = nil
+		instance.deliveries[delivery.ID] = &copied
+	}
+
+	return nil
+}
+
+// ClaimDelivery is postponing a due delivery to until so no other worker attempts it meanwhile,
+// it fails with domain.ErrDeliveryClaimed when the delivery isn't due anymore
+func (instance *webhookMemory) ClaimDelivery(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time, until time.Time) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	stored, ok := instance.deliveries[delivery.ID]
+	if !ok || stored.Status != domain.DeliveryPending || stored.NextAttemptAt.After(now) {
+		return domain.ErrDeliveryClaimed
+	}
+
+	stored.NextAttemptAt = until
+	delivery.NextAttemptAt = until
+
+	return nil
+}
+
+// UpdateDelivery is saving the outcome of an attempt
+func (instance *webhookMemory) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	if _, ok := instance.deliveries[delivery.ID]; ok {
+		copied := *delivery
+		copied.Webhook = nil
+		instance.deliveries[delivery.ID] = &copied
+	}
+
+	return nil
+}
+
+// GetAllDueDeliveries is getting the pending deliveries whose next attempt time came, with their webhook
+func (instance *webhookMemory) GetAllDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var due []*domain.WebhookDelivery
+	for _, delivery := range instance.deliveries {
+		if delivery.Status != domain.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		copied := *delivery
+		if webhook, ok := instance.webhooks[delivery.WebhookID]; ok {
+			copiedWebhook := *webhook
+			copied.Webhook = &copiedWebhook
+		}
+		due = append(due, &copied)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+// GetAllDeliveriesWithPaginate is getting the delivery log of webhook, newest first
+func (instance *webhookMemory) GetAllDeliveriesWithPaginate(ctx context.Context, webhook *domain.Webhook, params *domain.WebhookDeliveryParams) ([]*domain.WebhookDelivery, int64, error) {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range instance.deliveries {
+		if delivery.WebhookID == webhook.ID {
+			copied := *delivery
+			deliveries = append(deliveries, &copied)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+
+	total := int64(len(deliveries))
+
+	offset := params.Limit * (params.Page - 1)
+	if offset < 0 || offset >= len(deliveries) {
+		return nil, total, nil
+	}
+
+	end := offset + params.Limit
+	if end > len(deliveries) {
+		end = len(deliveries)
+	}
+
+	return deliveries[offset:end], total, nil
+}
@@ -0,0 +1,162 @@
+package webhookrps
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/adapter/outbound/scope"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type webhookPostgres struct {
+	postgres *gorm.DB
+}
+
+func NewWebhookPostgres(postgres *gorm.DB) ports.WebhookRepository {
+	return &webhookPostgres{
+		postgres: postgres,
+	}
+}
+
+func (instance *webhookPostgres) Create(ctx context.Context, webhook *domain.Webhook) error {
+	return instance.postgres.Debug().Create(webhook).Error
+}
+
+func (instance *webhookPostgres) Update(ctx context.Context, webhook *domain.Webhook) error {
+	webhook.UpdatedAt = time.Now()
+
+	return instance.postgres.Debug().Model(&domain.Webhook{}).
+		Where("id = ?", webhook.ID).
+		Updates(map[string]interface{}{
+			"url":        webhook.URL,
+			"events":     webhook.Events,
+			"is_active":  webhook.IsActive,
+			"updated_at": webhook.UpdatedAt,
+		}).Error
+}
+
+// Delete is deleting webhook and its delivery log
+func (instance *webhookPostgres) Delete(ctx context.Context, webhook *domain.Webhook) error {
+	if err := instance.postgres.Debug().Transaction(func(tx *gorm.DB) error {
+		// delete deliveries
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+
+		// delete webhook
+		if err := tx.Where("id = ?", webhook.ID).Delete(&domain.Webhook{}).Error; err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (instance *webhookPostgres) GetOneByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	var webhook *domain.Webhook
+
+	if err := instance.postgres.Debug().Scopes(scope.Owner(ctx)).Where("id = ?", id).First(&webhook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (instance *webhookPostgres) GetAll(ctx context.Context) ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+
+	if err := instance.postgres.Debug().Scopes(scope.Owner(ctx)).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// CreateDeliveries skips the deliveries of an event already queued for their webhook
+func (instance *webhookPostgres) CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return instance.postgres.Debug().Omit("Webhook").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&deliveries).Error
+}
+
+// ClaimDelivery is postponing a due delivery to until so no other worker attempts it meanwhile,
+// it fails with domain.ErrDeliveryClaimed when the delivery isn't due anymore
+func (instance *webhookPostgres) ClaimDelivery(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time, until time.Time) error {
+	result := instance.postgres.Debug().Model(&domain.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, domain.DeliveryPending, now.UTC()).
+		Update("next_attempt_at", until.UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDeliveryClaimed
+	}
+
+	delivery.NextAttemptAt = until
+
+	return nil
+}
+
+// UpdateDelivery is saving the outcome of an attempt
+func (instance *webhookPostgres) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return instance.postgres.Debug().Model(&domain.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"response_status": delivery.ResponseStatus,
+			"error":           delivery.Error,
+			"next_attempt_at": delivery.NextAttemptAt.UTC(),
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+}
+
+// GetAllDueDeliveries is getting the pending deliveries whose next attempt time came, with their webhook
+func (instance *webhookPostgres) GetAllDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+
+	if err := instance.postgres.Debug().Preload("Webhook").
+		Where("status = ? AND next_attempt_at <= ?", domain.DeliveryPending, now.UTC()).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// GetAllDeliveriesWithPaginate is getting the delivery log of webhook, newest first
+func (instance *webhookPostgres) GetAllDeliveriesWithPaginate(ctx context.Context, webhook *domain.Webhook, params *domain.WebhookDeliveryParams) ([]*domain.WebhookDelivery, int64, error) {
+	var (
+		deliveries []*domain.WebhookDelivery
+		total      int64
+	)
+
+	q := instance.postgres.Debug().Model(&domain.WebhookDelivery{}).Where("webhook_id = ?", webhook.ID)
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := q.Order("id DESC").Limit(params.Limit).Offset(params.Limit * (params.Page - 1)).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
@@ -1,29 +1,159 @@
 package app
 
 import (
+	"github.com/go-redis/redis/v8"
+	"github.com/todo-list/internal/adapter/inbound/apikeyhdl"
+	"github.com/todo-list/internal/adapter/inbound/authhdl"
+	"github.com/todo-list/internal/adapter/inbound/middleware"
+	"github.com/todo-list/internal/adapter/inbound/projecthdl"
 	"github.com/todo-list/internal/adapter/inbound/taskhdl"
+	"github.com/todo-list/internal/adapter/inbound/webhookhdl"
+	"github.com/todo-list/internal/adapter/outbound/apikeyrps"
+	"github.com/todo-list/internal/adapter/outbound/eventpub"
+	"github.com/todo-list/internal/adapter/outbound/outboxrps"
+	"github.com/todo-list/internal/adapter/outbound/projectrps"
+	"github.com/todo-list/internal/adapter/outbound/reminderntf"
 	"github.com/todo-list/internal/adapter/outbound/taskrps"
+	"github.com/todo-list/internal/adapter/outbound/userrps"
+	"github.com/todo-list/internal/adapter/outbound/webhookhttp"
+	"github.com/todo-list/internal/adapter/outbound/webhookrps"
+	"github.com/todo-list/internal/core/ports"
+	"github.com/todo-list/internal/core/services/authsvc"
+	"github.com/todo-list/internal/core/services/outboxsvc"
+	"github.com/todo-list/internal/core/services/projectsvc"
+	"github.com/todo-list/internal/core/services/remindersvc"
 	"github.com/todo-list/internal/core/services/tasksvc"
+	"github.com/todo-list/internal/core/services/webhooksvc"
 	"gorm.io/gorm"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
+
+	PublisherLog   = "log"
+	PublisherRedis = "redis"
+	PublisherHTTP  = "http"
+)
+
+// Outbox configures the publisher the outbox events are relayed to, Publisher is log (default),
+// redis or http. A failed event is retried after Backoff doubled on every attempt until MaxAttempts.
+type Outbox struct {
+	Publisher   string
+	Redis       *redis.Client
+	Stream      string
+	MaxLen      int64
+	URL         string
+	Timeout     time.Duration
+	Backoff     time.Duration
+	MaxAttempts int
+}
+
 type Handlers struct {
+	Storage  string
 	Postgres *gorm.DB
+	SQLite   *gorm.DB
+	Redis    *redis.Client
+	CacheTTL time.Duration
 	R        *fiber.App
 	Logger   *zap.Logger
+
+	// ReminderOffsets are the durations before the action time of a task its reminders are sent
+	ReminderOffsets []time.Duration
+	// ReminderRetry configures the retries of the reminders not sent
+	ReminderRetry remindersvc.Retry
+	// WebhookRetry configures the request timeout and the retries of the webhook deliveries
+	WebhookRetry webhooksvc.Retry
+	// Tokens configures the access & refresh tokens of the users
+	Tokens authsvc.Tokens
+	Outbox Outbox
+
+	taskService     ports.TaskService
+	reminderService ports.ReminderService
+	webhookService  ports.WebhookService
+	outboxService   ports.OutboxService
+	authService     ports.AuthService
+	apiKeyService   ports.APIKeyService
+	projectService  ports.ProjectService
 }
 
 func (h *Handlers) SetupRouter() {
 
 	// initialize Repository
-	taskRepo := taskrps.NewTaskPostgres(h.Postgres)
+	var (
+		taskRepo    ports.TaskRepository
+		webhookRepo ports.WebhookRepository
+		outboxRepo  ports.OutboxRepository
+		userRepo    ports.UserRepository
+		apiKeyRepo  ports.APIKeyRepository
+		projectRepo ports.ProjectRepository
+	)
+	switch h.Storage {
+	case StorageMemory:
+		outboxRepo = outboxrps.NewOutboxMemory()
+		taskRepo = taskrps.NewTaskMemory(outboxRepo)
+		webhookRepo = webhookrps.NewWebhookMemory()
+		userRepo = userrps.NewUserMemory()
+		apiKeyRepo = apikeyrps.NewAPIKeyMemory()
+		projectRepo = projectrps.NewProjectMemory()
+	case StorageSQLite:
+		// the postgres repositories are written in portable sql, the tasks override their dialect specific queries
+		taskRepo = taskrps.NewTaskSQLite(h.SQLite)
+		webhookRepo = webhookrps.NewWebhookPostgres(h.SQLite)
+		outboxRepo = outboxrps.NewOutboxPostgres(h.SQLite)
+		userRepo = userrps.NewUserPostgres(h.SQLite)
+		apiKeyRepo = apikeyrps.NewAPIKeyPostgres(h.SQLite)
+		projectRepo = projectrps.NewProjectPostgres(h.SQLite)
+	default:
+		taskRepo = taskrps.NewTaskPostgres(h.Postgres)
+		webhookRepo = webhookrps.NewWebhookPostgres(h.Postgres)
+		outboxRepo = outboxrps.NewOutboxPostgres(h.Postgres)
+		userRepo = userrps.NewUserPostgres(h.Postgres)
+		apiKeyRepo = apikeyrps.NewAPIKeyPostgres(h.Postgres)
+		projectRepo = projectrps.NewProjectPostgres(h.Postgres)
+	}
+	if h.Redis != nil {
+		taskRepo = taskrps.NewTaskCache(h.Redis, h.CacheTTL, taskRepo)
+	}
+
+	// initialize Publisher
+	var publisher ports.EventPublisher
+	switch h.Outbox.Publisher {
+	case PublisherRedis:
+		publisher = eventpub.NewRedisPublisher(h.Outbox.Redis, h.Outbox.Stream, h.Outbox.MaxLen)
+	case PublisherHTTP:
+		publisher = eventpub.NewHTTPPublisher(h.Outbox.URL, h.Outbox.Timeout)
+	default:
+		publisher = eventpub.NewLogPublisher(h.Logger)
+	}
 
 	// initialize Service
-	taskService := tasksvc.NewTaskService(h.Logger, taskRepo)
+	h.webhookService = webhooksvc.NewWebhookService(h.Logger, webhookRepo, webhookhttp.NewHTTPSender(h.WebhookRetry.Timeout), h.WebhookRetry)
+	h.outboxService = outboxsvc.NewOutboxService(h.Logger, outboxRepo, publisher, webhooksvc.NewEventService(h.Logger, webhookRepo), outboxsvc.Retry{
+		Backoff:     h.Outbox.Backoff,
+		MaxAttempts: h.Outbox.MaxAttempts,
+		Timeout:     h.Outbox.Timeout,
+	})
+	h.taskService = tasksvc.NewTaskService(h.Logger, taskRepo, projectRepo)
+	h.projectService = projectsvc.NewProjectService(h.Logger, projectRepo, taskRepo)
+	h.authService = authsvc.NewAuthService(h.Logger, userRepo, apiKeyRepo, h.Tokens)
+	h.apiKeyService = authsvc.NewAPIKeyService(h.Logger, apiKeyRepo)
+	h.reminderService = remindersvc.NewReminderService(h.Logger, taskRepo, reminderntf.NewLogNotifier(h.Logger), h.ReminderOffsets, h.ReminderRetry)
 
 	// initialize Handler
-	taskhdl.NewTaskHandler(h.R, taskService)
+	h.R.Use(middleware.Actor())
+	authhdl.NewAuthHandler(h.R, h.authService)
+	h.R.Use("/task", middleware.AuthWithAPIKey(h.authService))
+	h.R.Use("/project", middleware.AuthWithAPIKey(h.authService))
+	h.R.Use("/webhook", middleware.Auth(h.authService))
+	h.R.Use("/apikey", middleware.Auth(h.authService))
+	taskhdl.NewTaskHandler(h.R, h.taskService)
+	projecthdl.NewProjectHandler(h.R, h.projectService)
+	webhookhdl.NewWebhookHandler(h.R, h.webhookService)
+	apikeyhdl.NewAPIKeyHandler(h.R, h.apiKeyService)
 }
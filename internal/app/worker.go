@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Workers configures the background jobs, a job with a zero interval isn't run
+type Workers struct {
+	RecurrenceInterval time.Duration
+	ReminderInterval   time.Duration
+	WebhookInterval    time.Duration
+	OutboxInterval     time.Duration
+}
+
+// StartWorkers runs the background jobs of the services set up by SetupRouter until ctx is done,
+// the returned wait group is done once every job returned
+func (h *Handlers) StartWorkers(ctx context.Context, workers Workers) *sync.WaitGroup {
+	wg := new(sync.WaitGroup)
+
+	if workers.RecurrenceInterval > 0 {
+		runEvery(ctx, wg, workers.RecurrenceInterval, func(ctx context.Context, now time.Time) {
+			if err := h.taskService.MaterializeDue(ctx, now); err != nil {
+				h.Logger.Error("failed to materialize recurring tasks : ", zap.Error(err))
+			}
+		})
+	}
+
+	if workers.ReminderInterval > 0 && len(h.ReminderOffsets) > 0 {
+		runEvery(ctx, wg, workers.ReminderInterval, func(ctx context.Context, now time.Time) {
+			if err := h.reminderService.SendDue(ctx, now); err != nil {
+				h.Logger.Error("failed to send reminders : ", zap.Error(err))
+			}
+		})
+	}
+
+	if workers.OutboxInterval > 0 {
+		runEvery(ctx, wg, workers.OutboxInterval, func(ctx context.Context, now time.Time) {
+			if err := h.outboxService.Relay(ctx, now); err != nil {
+				h.Logger.Error("failed to relay outbox events : ", zap.Error(err))
+			}
+		})
+	}
+
+	if workers.WebhookInterval > 0 {
+		runEvery(ctx, wg, workers.WebhookInterval, func(ctx context.Context, now time.Time) {
+			if err := h.webhookService.DeliverDue(ctx, now); err != nil {
+				h.Logger.Error("failed to deliver webhooks : ", zap.Error(err))
+			}
+		})
+	}
+
+	return wg
+}
+
+// runEvery calls job every interval in its own goroutine, a run is never interrupted by ctx
+// so it can finish its writes. A job works for every user, it sees the rows of all of them.
+func runEvery(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, job func(ctx context.Context, now time.Time)) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				job(domain.WithSystem(context.Background()), now)
+			}
+		}
+	}()
+}
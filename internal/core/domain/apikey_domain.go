@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"strings"
+	"time"
+)
+
+const (
+	ScopeRead      = "read"
+	ScopeReadWrite = "read-write"
+
+	// APIKeyPrefix starts every API key, it tells them apart from the access tokens
+	APIKeyPrefix = "tdl_"
+
+	// apiKeyTouchInterval is how often the last used time of a key is written
+	apiKeyTouchInterval = time.Minute
+)
+
+var ErrExpiryInPast = errors.New("must be in the future")
+
+// APIKey authenticates the scripts of its owner, only the SHA-256 of the key is stored since the
+// keys are random and long enough to not need a slow hash
+type APIKey struct {
+	ID         uint64
+	OwnerID    uint64
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scope      string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// HashAPIKey is the hash an API key is stored and looked up by
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether credential looks like an API key rather than an access token
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Allows reports whether the scope of the key allows a request of method, read keys may only
+// read
+func (k *APIKey) Allows(method string) bool {
+	if k.Scope == ScopeReadWrite {
+		return true
+	}
+
+	return method == "GET" || method == "HEAD"
+}
+
+// Used sets the last used time of the key and reports whether it has to be saved, it is only
+// written once per apiKeyTouchInterval so every request doesn't write
+func (k *APIKey) Used(now time.Time) bool {
+	if k.LastUsedAt != nil && now.Sub(*k.LastUsedAt) < apiKeyTouchInterval {
+		return false
+	}
+
+	k.LastUsedAt = &now
+	return true
+}
+
+type APIKeyTransformer struct {
+	ID         uint64 `json:"API_Key_ID"`
+	Name       string `json:"Name"`
+	Prefix     string `json:"Prefix"`
+	Scope      string `json:"Scope"`
+	ExpiresAt  *int64 `json:"Expires_Time"`
+	LastUsedAt *int64 `json:"Last_Used_Time"`
+	CreatedAt  int64  `json:"Created_Time"`
+	// Key is only returned once, when the key is created
+	Key string `json:"Key,omitempty"`
+}
+
+func (k *APIKey) ToAPIKeyTransformer() *APIKeyTransformer {
+	transformer := &APIKeyTransformer{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scope:     k.Scope,
+		CreatedAt: k.CreatedAt.Unix(),
+	}
+	if k.ExpiresAt != nil {
+		unix := k.ExpiresAt.Unix()
+		transformer.ExpiresAt = &unix
+	}
+	if k.LastUsedAt != nil {
+		unix := k.LastUsedAt.Unix()
+		transformer.LastUsedAt = &unix
+	}
+
+	return transformer
+}
+
+// CreateAPIKeyRequest creates a key that never expires when Expires_Time is not given
+type CreateAPIKeyRequest struct {
+	Name      string `json:"Name"`
+	Scope     string `json:"Scope"`
+	ExpiresAt *int64 `json:"Expires_Time"`
+}
+
+func (c CreateAPIKeyRequest) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&c.Scope, validation.Required, validation.In(ScopeRead, ScopeReadWrite)),
+		validation.Field(&c.ExpiresAt, validation.By(inFuture)),
+	)
+}
+
+// ToBase returns the key with the plain key, which is never stored
+func (c *CreateAPIKeyRequest) ToBase(owner uint64) (*APIKey, string, error) {
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	key := APIKeyPrefix + hex.EncodeToString(random)
+
+	apiKey := &APIKey{
+		OwnerID: owner,
+		Name:    c.Name,
+		Prefix:  key[:len(APIKeyPrefix)+8],
+		KeyHash: HashAPIKey(key),
+		Scope:   c.Scope,
+	}
+	if c.ExpiresAt != nil {
+		expiresAt := time.Unix(*c.ExpiresAt, 0).UTC()
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	return apiKey, key, nil
+}
+
+func inFuture(value interface{}) error {
+	value, isNil := validation.Indirect(value)
+	unix, _ := value.(int64)
+	if isNil {
+		return nil
+	}
+
+	if unix <= time.Now().Unix() {
+		return ErrExpiryInPast
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyAllows(t *testing.T) {
+	read := &APIKey{Scope: ScopeRead}
+	assert.True(t, read.Allows("GET"))
+	assert.True(t, read.Allows("HEAD"))
+	assert.False(t, read.Allows("POST"))
+	assert.False(t, read.Allows("PATCH"))
+	assert.False(t, read.Allows("DELETE"))
+
+	readWrite := &APIKey{Scope: ScopeReadWrite}
+	assert.True(t, readWrite.Allows("DELETE"))
+}
+
+func TestAPIKeyIsExpired(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	apiKey := &APIKey{ExpiresAt: &expiresAt}
+
+	assert.False(t, apiKey.IsExpired(now))
+	assert.True(t, apiKey.IsExpired(expiresAt))
+	assert.False(t, (&APIKey{}).IsExpired(now.AddDate(100, 0, 0)))
+}
+
+func TestAPIKeyUsed(t *testing.T) {
+	now := time.Now()
+	apiKey := &APIKey{}
+
+	assert.True(t, apiKey.Used(now))
+	assert.False(t, apiKey.Used(now.Add(30*time.Second)))
+	assert.True(t, apiKey.Used(now.Add(time.Minute)))
+	assert.Equal(t, now.Add(time.Minute), *apiKey.LastUsedAt)
+}
+
+func TestCreateAPIKeyRequest(t *testing.T) {
+	past := time.Now().Add(-time.Minute).Unix()
+	assert.Error(t, CreateAPIKeyRequest{Name: "script", Scope: ScopeRead, ExpiresAt: &past}.Validate())
+	assert.Error(t, CreateAPIKeyRequest{Name: "script", Scope: "admin"}.Validate())
+
+	future := time.Now().Add(time.Hour).Unix()
+	request := &CreateAPIKeyRequest{Name: "script", Scope: ScopeRead, ExpiresAt: &future}
+	require.NoError(t, request.Validate())
+
+	// only the hash of the key is kept
+	apiKey, key, err := request.ToBase(7)
+	require.NoError(t, err)
+	assert.True(t, IsAPIKey(key))
+	assert.Equal(t, HashAPIKey(key), apiKey.KeyHash)
+	assert.NotContains(t, apiKey.KeyHash, key[len(APIKeyPrefix):])
+	assert.Equal(t, uint64(7), apiKey.OwnerID)
+	assert.Equal(t, future, apiKey.ExpiresAt.Unix())
+}
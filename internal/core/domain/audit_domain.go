@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"time"
+)
+
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+
+	// ActorKey is the context key of the actor the changes are made by
+	ActorKey = "actor"
+	// ActorSystem is the actor of the changes made by the background jobs
+	ActorSystem = "system"
+	// ActorAnonymous is the actor of the requests made without credentials
+	ActorAnonymous = "anonymous"
+)
+
+// ActorFromContext is the actor set on ctx, changes without one are made by the system
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(ActorKey).(string); ok && actor != "" {
+		return actor
+	}
+
+	return ActorSystem
+}
+
+// AuditLog is one change of a task with the snapshots of the task & objectives before and after it,
+// a created task has no before and a purged one no after
+type AuditLog struct {
+	ID        uint64
+	TaskID    uint64
+	OwnerID   uint64
+	Action    string
+	Actor     string
+	Version   uint64
+	Before    *string
+	After     *string
+	CreatedAt time.Time
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+func NewAuditLog(ctx context.Context, action string, before *Task, after *Task) (*AuditLog, error) {
+	audit := &AuditLog{
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	var err error
+	if before != nil {
+		audit.TaskID = before.ID
+		audit.OwnerID = before.OwnerID
+		audit.Version = before.Version
+		if audit.Before, err = snapshotTask(before); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		audit.TaskID = after.ID
+		audit.OwnerID = after.OwnerID
+		audit.Version = after.Version
+		if audit.After, err = snapshotTask(after); err != nil {
+			return nil, err
+		}
+	}
+
+	return audit, nil
+}
+
+// snapshotTask is the task & objectives as they are responded
+func snapshotTask(task *Task) (*string, error) {
+	encoded, err := json.Marshal(task.ToTaskTransformer())
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := string(encoded)
+	return &snapshot, nil
+}
+
+type AuditLogTransformer struct {
+	ID        uint64          `json:"Audit_ID"`
+	TaskID    uint64          `json:"Task_ID"`
+	Action    string          `json:"Action"`
+	Actor     string          `json:"Actor"`
+	Version   uint64          `json:"Version"`
+	Before    json.RawMessage `json:"Before"`
+	After     json.RawMessage `json:"After"`
+	CreatedAt int64           `json:"Created_Time"`
+}
+
+func (a *AuditLog) ToAuditLogTransformer() *AuditLogTransformer {
+	return &AuditLogTransformer{
+		ID:        a.ID,
+		TaskID:    a.TaskID,
+		Action:    a.Action,
+		Actor:     a.Actor,
+		Version:   a.Version,
+		Before:    rawSnapshot(a.Before),
+		After:     rawSnapshot(a.After),
+		CreatedAt: a.CreatedAt.Unix(),
+	}
+}
+
+// rawSnapshot is null when there is no snapshot
+func rawSnapshot(snapshot *string) json.RawMessage {
+	if snapshot == nil {
+		return json.RawMessage("null")
+	}
+
+	return json.RawMessage(*snapshot)
+}
+
+type AuditLogParams struct {
+	Page  int `query:"Page"`
+	Limit int `query:"Limit"`
+}
+
+func (p AuditLogParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Page, validation.Required, validation.By(moreThanNol)),
+		validation.Field(&p.Limit, validation.Required, validation.By(moreThanNol)),
+	)
+}
+
+type AuditLogPagination struct {
+	ListData       []*AuditLogTransformer `json:"List_Data"`
+	PaginationData *Pagination            `json:"Pagination_Data"`
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+
+	// BatchAllOrNothing rolls every operation back when one fails, BatchPerItem only rolls back the
+	// failed ones
+	BatchAllOrNothing = "all_or_nothing"
+	BatchPerItem      = "per_item"
+
+	// BatchSucceeded and BatchFailed are the status of an operation that was run, a rolled back
+	// operation succeeded but was undone by the failure of another and a skipped one wasn't run
+	BatchSucceeded  = "succeeded"
+	BatchFailed     = "failed"
+	BatchRolledBack = "rolled_back"
+	BatchSkipped    = "skipped"
+
+	maxBatchOperations = 500
+)
+
+var ErrBatchMissingTask = errors.New("task is required")
+
+type BatchRequest struct {
+	Mode       string           `json:"Mode"`
+	Operations []BatchOperation `json:"Operations"`
+}
+
+func (b BatchRequest) Validate() error {
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.Mode, validation.Required, validation.In(BatchAllOrNothing, BatchPerItem)),
+		validation.Field(&b.Operations, validation.Required, validation.Length(1, maxBatchOperations)),
+	)
+}
+
+// BatchOperation is one create, update or delete of a batch, If_Match and Scope are the header and
+// query of the matching single task endpoint. Task is its body, it is only decoded when the
+// operation is run so an invalid task fails alone.
+type BatchOperation struct {
+	Method  string          `json:"Method"`
+	TaskID  uint64          `json:"Task_ID"`
+	IfMatch string          `json:"If_Match"`
+	Scope   string          `json:"Scope"`
+	Task    json.RawMessage `json:"Task"`
+}
+
+func (o BatchOperation) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Method, validation.Required, validation.In(BatchCreate, BatchUpdate, BatchDelete)),
+		validation.Field(&o.TaskID, validation.When(o.Method != BatchCreate, validation.Required)),
+		validation.Field(&o.Scope, validation.In(ScopeThis, ScopeAll).Error(ErrInvalidScope.Error())),
+	)
+}
+
+// CreateRequest decodes and validates Task as the body of a create
+func (o *BatchOperation) CreateRequest() (*CreateTaskRequst, error) {
+	request := new(CreateTaskRequst)
+	if err := o.decodeTask(request); err != nil {
+		return nil, err
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// UpdateRequest decodes and validates Task as the body of an update
+func (o *BatchOperation) UpdateRequest() (*UpdateTaskRequest, error) {
+	request := new(UpdateTaskRequest)
+	if err := o.decodeTask(request); err != nil {
+		return nil, err
+	}
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (o *BatchOperation) decodeTask(request interface{}) error {
+	if len(o.Task) == 0 || string(o.Task) == "null" {
+		return ErrBatchMissingTask
+	}
+
+	return json.Unmarshal(o.Task, request)
+}
+
+// BatchResult is the outcome of the operation at Index, Task_ID is the id of the task it was run on
+// or of the task it created
+type BatchResult struct {
+	Index        int    `json:"Index"`
+	Method       string `json:"Method"`
+	TaskID       uint64 `json:"Task_ID,omitempty"`
+	Status       string `json:"Status"`
+	ErrorKey     string `json:"Error_Key,omitempty"`
+	ErrorMessage string `json:"Error_Message,omitempty"`
+}
+
+// BatchTransformer tells whether the transaction of the batch was committed, an all or nothing
+// batch isn't when any operation failed
+type BatchTransformer struct {
+	Mode      string         `json:"Mode"`
+	Committed bool           `json:"Committed"`
+	Succeeded int            `json:"Succeeded"`
+	Failed    int            `json:"Failed"`
+	Results   []*BatchResult `json:"Results"`
+}
+
+// NewBatchTransformer returns the results of the operations of request, all skipped until they are run
+func NewBatchTransformer(request *BatchRequest) *BatchTransformer {
+	transformer := &BatchTransformer{
+		Mode:    request.Mode,
+		Results: make([]*BatchResult, len(request.Operations)),
+	}
+	for i, operation := range request.Operations {
+		transformer.Results[i] = &BatchResult{
+			Index:  i,
+			Method: operation.Method,
+			Status: BatchSkipped,
+		}
+	}
+
+	return transformer
+}
+
+// RollBack marks the operations that succeeded as rolled back once the transaction was
+func (b *BatchTransformer) RollBack() {
+	b.Committed = false
+	for _, result := range b.Results {
+		if result.Status == BatchSucceeded {
+			result.Status = BatchRolledBack
+		}
+		if result.Method == BatchCreate {
+			result.TaskID = 0
+		}
+	}
+}
+
+// Count sets Succeeded and Failed from the results
+func (b *BatchTransformer) Count() {
+	b.Succeeded, b.Failed = 0, 0
+	for _, result := range b.Results {
+		switch result.Status {
+		case BatchSucceeded:
+			b.Succeeded++
+		case BatchFailed:
+			b.Failed++
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+)
+
+var (
+	ErrDependencyExists   = errors.New("task is already blocked by this task")
+	ErrDependencyNotFound = errors.New("task isn't blocked by this task")
+	ErrDependencyMissing  = errors.New("task or blocker doesn't exist")
+)
+
+// TaskDependency is a row of the dependency table, the task can't be finished before its blocker is
+type TaskDependency struct {
+	TaskID    uint64
+	BlockerID uint64
+}
+
+// GetBlockedBy is never nil, so a task without blockers is responded with an empty list
+func (t *Task) GetBlockedBy() []uint64 {
+	if t.BlockedBy == nil {
+		return []uint64{}
+	}
+
+	return t.BlockedBy
+}
+
+// IsBlockedBy reports whether one of blockers, the tasks blocking this one, is still open
+func (t *Task) IsBlockedBy(blockers []*Task) bool {
+	for _, blocker := range blockers {
+		if blocker.DeletedAt == nil && !blocker.IsFinished {
+			return true
+		}
+	}
+
+	return false
+}
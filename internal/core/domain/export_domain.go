@@ -0,0 +1,601 @@
+package domain
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	// objectiveFinished and objectiveOpen start the lines of the objective list of a csv row
+	objectiveFinished = "[x] "
+	objectiveOpen     = "[ ] "
+
+	// formulaEscape starts a csv cell a spreadsheet would run as a formula, it is removed on import.
+	// A cell already starting with it is escaped too so the import doesn't remove its own.
+	formulaEscape = "'"
+	formulaStarts = "=+-@\t\r"
+
+	maxImportRows = 5000
+)
+
+var (
+	ErrInvalidFormat    = errors.New("format must be csv or json")
+	ErrTooManyRows      = errors.New("a file can't have more than 5000 tasks")
+	ErrMissingCSVHeader = errors.New("the first row must be the header")
+	ErrDuplicateTaskID  = errors.New("Task_ID: is already used by another row.")
+	ErrObjectiveNewline = errors.New("must be on one line")
+
+	// taskRecordColumns is the header of a csv file, its columns may be in any order when imported
+	taskRecordColumns = []string{"Task_ID", "Parent_ID", "Project_ID", "Title", "Priority", "Action_Time", "Due_Time",
+		"Is_Finished", "Recurrence", "Recurrence_Start", "Has_Next", "Tags", "Objective_List"}
+)
+
+// ValidFormat accepts an empty format, it defaults to json
+func ValidFormat(format string) error {
+	return validation.Validate(format, validation.In(FormatCSV, FormatJSON).Error(ErrInvalidFormat.Error()))
+}
+
+// TaskRecord is a task of an exported or imported file. Task_ID is the id of the task where it was
+// exported from, a Parent_ID naming the Task_ID of another row of an imported file is its parent,
+// any other Parent_ID is an existing task. Recurrence_Start and Has_Next keep an occurrence in its
+// series, an occurrence with Has_Next already has its next one and none is created for it.
+type TaskRecord struct {
+	ID              uint64            `json:"Task_ID"`
+	ParentID        *uint64           `json:"Parent_ID"`
+	ProjectID       *uint64           `json:"Project_ID"`
+	Title           string            `json:"Title"`
+	Priority        string            `json:"Priority"`
+	ActionTime      int64             `json:"Action_Time"`
+	DueTime         *int64            `json:"Due_Time"`
+	IsFinished      bool              `json:"Is_Finished"`
+	Recurrence      *string           `json:"Recurrence"`
+	RecurrenceStart *int64            `json:"Recurrence_Start"`
+	HasNext         bool              `json:"Has_Next"`
+	Tags            []string          `json:"Tags"`
+	Objectives      []ObjectiveRecord `json:"Objective_List"`
+}
+
+type ObjectiveRecord struct {
+	ObjectiveName string `json:"Objective_Name"`
+	IsFinished    bool   `json:"Is_Finished"`
+}
+
+func (o ObjectiveRecord) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.ObjectiveName, validation.Required, validation.By(singleLine)),
+	)
+}
+
+// singleLine rejects a line break, a csv file has one objective per line
+func singleLine(value interface{}) error {
+	text, _ := value.(string)
+	if strings.ContainsAny(text, "\r\n") {
+		return ErrObjectiveNewline
+	}
+
+	return nil
+}
+
+func (r TaskRecord) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Title, validation.Required),
+		validation.Field(&r.Priority, validation.In(PriorityNames...)),
+		validation.Field(&r.DueTime, validation.Min(int64(0))),
+		validation.Field(&r.Recurrence, validation.By(validRecurrence)),
+		validation.Field(&r.RecurrenceStart, validation.Min(int64(0))),
+		validation.Field(&r.Tags, validation.By(validTags)),
+		validation.Field(&r.Objectives),
+	)
+}
+
+func (t *Task) ToTaskRecord() *TaskRecord {
+	record := &TaskRecord{
+		ID:         t.ID,
+		ParentID:   t.ParentID,
+		ProjectID:  t.ProjectID,
+		Title:      t.Title,
+		Priority:   t.Priority.String(),
+		ActionTime: t.ActionTime.Unix(),
+		DueTime:    t.GetDueTime(),
+		IsFinished: t.IsFinished,
+		Recurrence: t.Recurrence,
+		HasNext:    t.HasNext,
+		Tags:       t.GetTags(),
+		Objectives: []ObjectiveRecord{},
+	}
+	if t.RecurrenceStart != nil {
+		start := t.RecurrenceStart.Unix()
+		record.RecurrenceStart = &start
+	}
+	for _, objective := range t.Objective {
+		record.Objectives = append(record.Objectives, ObjectiveRecord{
+			ObjectiveName: objective.ObjectiveName,
+			IsFinished:    objective.IsFinished,
+		})
+	}
+
+	return record
+}
+
+// ToBase returns the task of the record, a task with objectives is finished when they all are. A
+// series without Recurrence_Start starts again from the task.
+func (r *TaskRecord) ToBase() *Task {
+	task := &Task{
+		Title:      r.Title,
+		Priority:   ParsePriority(r.Priority),
+		ActionTime: time.Unix(r.ActionTime, 0).UTC(),
+		IsFinished: r.IsFinished,
+		Version:    1,
+		Tags:       NormalizeTags(r.Tags),
+	}
+	for _, objective := range r.Objectives {
+		task.Objective = append(task.Objective, &Objective{
+			ObjectiveName: objective.ObjectiveName,
+			IsFinished:    objective.IsFinished,
+		})
+	}
+	if len(task.Objective) > 0 {
+		task.IsFinished = task.IsAllObjectivesFinished()
+	}
+	task.SetDue(r.DueTime)
+	task.SetRecurrence(r.Recurrence)
+	if task.Recurrence != nil {
+		if r.RecurrenceStart != nil {
+			start := time.Unix(*r.RecurrenceStart, 0).UTC()
+			task.RecurrenceStart = &start
+		}
+		task.HasNext = r.HasNext
+	}
+	task.SetProject(r.ProjectID)
+	task.SetParent(r.ParentID)
+
+	return task
+}
+
+// TaskRecordWriter writes the tasks of an export, Close must be called once they are all written
+type TaskRecordWriter interface {
+	Write(record *TaskRecord) error
+	// Flush writes the buffered records through to the underlying writer
+	Flush() error
+	Close() error
+}
+
+func NewTaskRecordWriter(format string, w io.Writer) TaskRecordWriter {
+	if format == FormatCSV {
+		return &csvRecordWriter{w: w, csv: csv.NewWriter(w)}
+	}
+
+	return &jsonRecordWriter{w: w}
+}
+
+type csvRecordWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	started bool
+}
+
+func (instance *csvRecordWriter) Write(record *TaskRecord) error {
+	if err := instance.start(); err != nil {
+		return err
+	}
+
+	objectives := make([]string, 0, len(record.Objectives))
+	for _, objective := range record.Objectives {
+		prefix := objectiveOpen
+		if objective.IsFinished {
+			prefix = objectiveFinished
+		}
+		// an objective of a line is never split, the name of an older task may have a line break
+		name := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(objective.ObjectiveName)
+		objectives = append(objectives, prefix+name)
+	}
+
+	recurrence := ""
+	if record.Recurrence != nil {
+		recurrence = *record.Recurrence
+	}
+
+	return instance.csv.Write(escapeFormulas([]string{
+		strconv.FormatUint(record.ID, 10),
+		formatOptionalUint(record.ParentID),
+		formatOptionalUint(record.ProjectID),
+		record.Title,
+		record.Priority,
+		strconv.FormatInt(record.ActionTime, 10),
+		formatOptionalInt(record.DueTime),
+		strconv.FormatBool(record.IsFinished),
+		recurrence,
+		formatOptionalInt(record.RecurrenceStart),
+		strconv.FormatBool(record.HasNext),
+		strings.Join(record.Tags, ","),
+		strings.Join(objectives, "\n"),
+	}))
+}
+
+// escapeFormulas prefixes the cells starting like a formula, so a spreadsheet shows them as text
+func escapeFormulas(cells []string) []string {
+	for i, cell := range cells {
+		if cell != "" && strings.ContainsRune(formulaStarts+formulaEscape, rune(cell[0])) {
+			cells[i] = formulaEscape + cell
+		}
+	}
+
+	return cells
+}
+
+// unescapeFormula removes the prefix escapeFormulas added
+func unescapeFormula(cell string) string {
+	if len(cell) > 1 && strings.HasPrefix(cell, formulaEscape) && strings.ContainsRune(formulaStarts+formulaEscape, rune(cell[1])) {
+		return cell[1:]
+	}
+
+	return cell
+}
+
+// start writes the header, an export without tasks still has one
+func (instance *csvRecordWriter) start() error {
+	if instance.started {
+		return nil
+	}
+	instance.started = true
+
+	return instance.csv.Write(taskRecordColumns)
+}
+
+func (instance *csvRecordWriter) Flush() error {
+	instance.csv.Flush()
+	if err := instance.csv.Error(); err != nil {
+		return err
+	}
+
+	return flush(instance.w)
+}
+
+func (instance *csvRecordWriter) Close() error {
+	if err := instance.start(); err != nil {
+		return err
+	}
+
+	return instance.Flush()
+}
+
+// jsonRecordWriter writes the records as a json array, one record per line
+type jsonRecordWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (instance *jsonRecordWriter) Write(record *TaskRecord) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n"
+	if !instance.started {
+		separator = "[\n"
+		instance.started = true
+	}
+
+	_, err = io.WriteString(instance.w, separator+string(raw))
+	return err
+}
+
+func (instance *jsonRecordWriter) Flush() error {
+	return flush(instance.w)
+}
+
+func (instance *jsonRecordWriter) Close() error {
+	end := "\n]\n"
+	if !instance.started {
+		end = "[]\n"
+	}
+
+	if _, err := io.WriteString(instance.w, end); err != nil {
+		return err
+	}
+
+	return instance.Flush()
+}
+
+// flush flushes w when it is buffered
+func flush(w io.Writer) error {
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+
+	return nil
+}
+
+// RowError is why the task at Row of an imported file wasn't imported, rows start at 1 and the
+// header of a csv file isn't counted
+type RowError struct {
+	Row          int    `json:"Row"`
+	ErrorMessage string `json:"Error_Message"`
+}
+
+// ImportTransformer tells whether the tasks of a file were imported, none is when any row failed
+type ImportTransformer struct {
+	Committed bool        `json:"Committed"`
+	Imported  int         `json:"Imported"`
+	Errors    []*RowError `json:"Errors"`
+}
+
+// ReadTaskRecords parses and validates every row of a file, the rows failing are reported in the
+// errors and are nil in the records. An error is only returned when the file can't be read at all.
+func ReadTaskRecords(format string, r io.Reader) ([]*TaskRecord, []*RowError, error) {
+	var (
+		records   []*TaskRecord
+		rowErrors []*RowError
+		err       error
+	)
+
+	if format == FormatCSV {
+		records, rowErrors, err = readCSVRecords(r)
+	} else {
+		records, rowErrors, err = readJSONRecords(r)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := map[uint64]int{}
+	for i, record := range records {
+		if record == nil {
+			continue
+		}
+
+		if err := record.Validate(); err != nil {
+			rowErrors = append(rowErrors, &RowError{Row: i + 1, ErrorMessage: err.Error()})
+			records[i] = nil
+			continue
+		}
+
+		if record.ID == 0 {
+			continue
+		}
+		if _, ok := rows[record.ID]; ok {
+			rowErrors = append(rowErrors, &RowError{Row: i + 1, ErrorMessage: ErrDuplicateTaskID.Error()})
+			records[i] = nil
+			continue
+		}
+		rows[record.ID] = i
+	}
+
+	return records, rowErrors, nil
+}
+
+func readJSONRecords(r io.Reader) ([]*TaskRecord, []*RowError, error) {
+	var (
+		records   []*TaskRecord
+		rowErrors []*RowError
+	)
+
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, nil, errors.New("the file must be a json array of tasks")
+	}
+
+	for decoder.More() {
+		if len(records) == maxImportRows {
+			return nil, nil, ErrTooManyRows
+		}
+
+		record := new(TaskRecord)
+		if err := decoder.Decode(record); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return nil, nil, err
+			}
+
+			// the value was read up to its end, the next rows can still be decoded
+			rowErrors = append(rowErrors, &RowError{Row: len(records) + 1, ErrorMessage: typeErr.Field + ": cannot be a " + typeErr.Value + "."})
+			record = nil
+		}
+		records = append(records, record)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	return records, rowErrors, nil
+}
+
+func readCSVRecords(r io.Reader) ([]*TaskRecord, []*RowError, error) {
+	var (
+		records   []*TaskRecord
+		rowErrors []*RowError
+	)
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, ErrMissingCSVHeader
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !isTaskRecordColumn(name) {
+			return nil, nil, fmt.Errorf("unknown column %q, the columns are %s", name, strings.Join(taskRecordColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["Title"]; !ok {
+		return nil, nil, errors.New("the Title column is missing")
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(records) == maxImportRows {
+			return nil, nil, ErrTooManyRows
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, nil, err
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, &RowError{Row: len(records) + 1, ErrorMessage: "must have " + strconv.Itoa(len(header)) + " columns"})
+			records = append(records, nil)
+			continue
+		}
+
+		record, err := parseCSVRecord(row, columns)
+		if err != nil {
+			rowErrors = append(rowErrors, &RowError{Row: len(records) + 1, ErrorMessage: err.Error()})
+		}
+		records = append(records, record)
+	}
+
+	return records, rowErrors, nil
+}
+
+// parseCSVRecord returns a nil record when a cell can't be parsed, an empty cell is a missing value
+func parseCSVRecord(row []string, columns map[string]int) (*TaskRecord, error) {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(unescapeFormula(strings.TrimSpace(row[i])))
+		}
+		return ""
+	}
+
+	record := &TaskRecord{
+		Title:    cell("Title"),
+		Priority: cell("Priority"),
+	}
+	cellErrors := validation.Errors{}
+
+	var err error
+	if value := cell("Task_ID"); value != "" {
+		if record.ID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			cellErrors["Task_ID"] = errors.New("must be an id")
+		}
+	}
+	if record.ParentID, err = parseOptionalUint(cell("Parent_ID")); err != nil {
+		cellErrors["Parent_ID"] = errors.New("must be an id")
+	}
+	if record.ProjectID, err = parseOptionalUint(cell("Project_ID")); err != nil {
+		cellErrors["Project_ID"] = errors.New("must be an id")
+	}
+	if value := cell("Action_Time"); value != "" {
+		if record.ActionTime, err = strconv.ParseInt(value, 10, 64); err != nil {
+			cellErrors["Action_Time"] = errors.New("must be a unix time")
+		}
+	}
+	if value := cell("Due_Time"); value != "" {
+		dueTime, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			cellErrors["Due_Time"] = errors.New("must be a unix time")
+		}
+		record.DueTime = &dueTime
+	}
+	if value := cell("Is_Finished"); value != "" {
+		if record.IsFinished, err = strconv.ParseBool(value); err != nil {
+			cellErrors["Is_Finished"] = errors.New("must be true or false")
+		}
+	}
+	if value := cell("Recurrence"); value != "" {
+		record.Recurrence = &value
+	}
+	if value := cell("Recurrence_Start"); value != "" {
+		recurrenceStart, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			cellErrors["Recurrence_Start"] = errors.New("must be a unix time")
+		}
+		record.RecurrenceStart = &recurrenceStart
+	}
+	if value := cell("Has_Next"); value != "" {
+		if record.HasNext, err = strconv.ParseBool(value); err != nil {
+			cellErrors["Has_Next"] = errors.New("must be true or false")
+		}
+	}
+	if value := cell("Tags"); value != "" {
+		record.Tags = strings.Split(value, ",")
+	}
+	record.Objectives = parseObjectiveList(cell("Objective_List"))
+
+	if len(cellErrors) > 0 {
+		return nil, cellErrors
+	}
+
+	return record, nil
+}
+
+// parseObjectiveList reads one objective per line, a line starting with [x] is finished
+func parseObjectiveList(value string) []ObjectiveRecord {
+	objectives := []ObjectiveRecord{}
+
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		objective := ObjectiveRecord{ObjectiveName: line}
+		switch {
+		case strings.HasPrefix(strings.ToLower(line), objectiveFinished):
+			objective.ObjectiveName = strings.TrimSpace(line[len(objectiveFinished):])
+			objective.IsFinished = true
+		case strings.HasPrefix(line, objectiveOpen):
+			objective.ObjectiveName = strings.TrimSpace(line[len(objectiveOpen):])
+		}
+		objectives = append(objectives, objective)
+	}
+
+	return objectives
+}
+
+func isTaskRecordColumn(name string) bool {
+	for _, column := range taskRecordColumns {
+		if column == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseOptionalUint(value string) (*uint64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+func formatOptionalUint(value *uint64) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatUint(*value, 10)
+}
+
+func formatOptionalInt(value *int64) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*value, 10)
+}
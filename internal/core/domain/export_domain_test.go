@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFormulaEscapeRoundTrip(t *testing.T) {
+	cells := []string{"=SUM(A1:A2)", "+1", "-1", "@cmd", "'=quoted", "'plain", "'", "plain", ""}
+	exported := escapeFormulas(append([]string{}, cells...))
+
+	assert.Equal(t, []string{"'=SUM(A1:A2)", "'+1", "'-1", "'@cmd", "''=quoted", "''plain", "''", "plain", ""}, exported)
+	for i, cell := range exported {
+		assert.Equal(t, cells[i], unescapeFormula(cell))
+	}
+}
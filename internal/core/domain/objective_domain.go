@@ -1,5 +1,12 @@
 package domain
 
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var ErrDuplicateObjectiveID = errors.New("an objective id can't be listed twice")
+
 type Objective struct {
 	ID            uint64
 	TaskID        uint64
@@ -10,18 +17,68 @@ type Objective struct {
 }
 
 type ObjectiveTransformer struct {
+	ID            uint64 `json:"Objective_ID"`
 	ObjectiveName string `json:"Objective_Name"`
 	IsFinished    bool   `json:"Is_Finished"`
 }
 
 func (o *Objective) ToObjectiveTransformer() *ObjectiveTransformer {
 	return &ObjectiveTransformer{
+		ID:            o.ID,
 		ObjectiveName: o.ObjectiveName,
 		IsFinished:    o.IsFinished,
 	}
 }
 
+// UpdateObjectiveRequest keeps the objective with ID, an objective without ID is created
 type UpdateObjectiveRequest struct {
+	ID            uint64 `json:"Objective_ID"`
 	ObjectiveName string `json:"Objective_Name"`
 	IsFinished    bool   `json:"Is_Finished"`
 }
+
+// uniqueObjectiveIDs rejects a list keeping the same objective twice
+func uniqueObjectiveIDs(value interface{}) error {
+	objectives, _ := value.([]UpdateObjectiveRequest)
+
+	seen := map[uint64]bool{}
+	for _, objective := range objectives {
+		if objective.ID == 0 {
+			continue
+		}
+		if seen[objective.ID] {
+			return ErrDuplicateObjectiveID
+		}
+		seen[objective.ID] = true
+	}
+
+	return nil
+}
+
+type CreateObjectiveRequest struct {
+	ObjectiveName string `json:"Objective_Name"`
+}
+
+func (c CreateObjectiveRequest) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.ObjectiveName, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+func (c *CreateObjectiveRequest) ToBase(task *Task) *Objective {
+	return &Objective{
+		TaskID:        task.ID,
+		ObjectiveName: c.ObjectiveName,
+		IsFinished:    false,
+	}
+}
+
+type RenameObjectiveRequest struct {
+	ObjectiveName string `json:"Objective_Name"`
+}
+
+func (r RenameObjectiveRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.ObjectiveName, validation.Required, validation.Length(1, 255)),
+	)
+}
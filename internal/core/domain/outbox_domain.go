@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrEventClaimed = errors.New("event was already claimed")
+
+// OutboxEvent is a domain event written in the same transaction as the task it describes,
+// it is published by the relay once the transaction committed
+type OutboxEvent struct {
+	ID          uint64
+	Type        string
+	TaskID      uint64
+	OwnerID     uint64
+	Payload     string
+	Attempts    int
+	Error       string
+	LockedUntil time.Time
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+	// DeadLetteredAt is set when the event ran out of attempts, it is not published anymore
+	DeadLetteredAt *time.Time
+}
+
+// Record adds an event of eventType to be written to the outbox with the next save of task
+func (t *Task) Record(eventType string) {
+	t.events = append(t.events, eventType)
+}
+
+// PullEvents builds the recorded events from the current state of task and forgets them,
+// it must be called by the repository once task is saved
+func (t *Task) PullEvents() ([]*OutboxEvent, error) {
+	var events []*OutboxEvent
+
+	now := time.Now().UTC()
+	for _, eventType := range t.events {
+		payload, err := json.Marshal(NewTaskEvent(eventType, t))
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, &OutboxEvent{
+			Type:        eventType,
+			TaskID:      t.ID,
+			OwnerID:     t.OwnerID,
+			Payload:     string(payload),
+			LockedUntil: now,
+			CreatedAt:   now,
+		})
+	}
+	t.events = nil
+
+	return events, nil
+}
+
+// Attempted records the outcome of one publication, a failed event is retried after backoff doubled
+// for every previous attempt and dead lettered once it failed maxAttempts times
+func (e *OutboxEvent) Attempted(err error, backoff time.Duration, maxAttempts int) {
+	now := time.Now().UTC()
+
+	e.Attempts++
+	e.LockedUntil = now
+
+	if err == nil {
+		e.Error = ""
+		e.PublishedAt = &now
+		return
+	}
+
+	e.Error = err.Error()
+	if e.Attempts >= maxAttempts {
+		e.DeadLetteredAt = &now
+		return
+	}
+
+	e.LockedUntil = now.Add(backoff * time.Duration(math.Pow(2, float64(e.Attempts-1))))
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+)
+
+// Priority is stored as its rank so the most urgent tasks are sorted first by the database
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// PriorityNames are the names of the priorities in the requests & responses, by rank
+var PriorityNames = []interface{}{"none", "low", "medium", "high", "urgent"}
+
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityUrgent {
+		return PriorityNames[PriorityNone].(string)
+	}
+
+	return PriorityNames[p].(string)
+}
+
+// ParsePriority returns the priority named name, an unknown name is no priority
+func ParsePriority(name string) Priority {
+	for rank, priorityName := range PriorityNames {
+		if priorityName == name {
+			return Priority(rank)
+		}
+	}
+
+	return PriorityNone
+}
+
+// ParsePriorities returns the priorities named names
+func ParsePriorities(names []string) []Priority {
+	var priorities []Priority
+	for _, name := range names {
+		priorities = append(priorities, ParsePriority(name))
+	}
+
+	return priorities
+}
+
+// SetDue sets the due date of the task, a nil or 0 unix time removes it
+func (t *Task) SetDue(unix *int64) {
+	if unix == nil || *unix == 0 {
+		t.DueAt = nil
+		return
+	}
+
+	dueAt := time.Unix(*unix, 0).UTC()
+	t.DueAt = &dueAt
+}
+
+// GetDueTime is the unix time of the due date, nil when the task has none
+func (t *Task) GetDueTime() *int64 {
+	if t.DueAt == nil {
+		return nil
+	}
+
+	unix := t.DueAt.Unix()
+	return &unix
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"time"
+)
+
+var ErrProjectNotEmpty = errors.New("a task still belongs to the project")
+
+// Project groups the tasks of a work stream, a task belongs to at most one project
+type Project struct {
+	ID        uint64
+	OwnerID   uint64
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ProjectCount is the number of tasks of a project by state, the trashed tasks are only counted
+// in Trashed
+type ProjectCount struct {
+	ProjectID uint64
+	Open      int64
+	Finished  int64
+	Trashed   int64
+}
+
+// IsEmpty reports whether no task, not even a trashed one, belongs to the project
+func (c *ProjectCount) IsEmpty() bool {
+	return c == nil || c.Open+c.Finished+c.Trashed == 0
+}
+
+type ProjectTransformer struct {
+	ID            uint64 `json:"Project_ID"`
+	Name          string `json:"Name"`
+	OpenTasks     int64  `json:"Open_Tasks"`
+	FinishedTasks int64  `json:"Finished_Tasks"`
+	CreatedAt     int64  `json:"Created_Time"`
+	UpdatedAt     int64  `json:"Updated_Time"`
+}
+
+func (p *Project) ToProjectTransformer(count *ProjectCount) *ProjectTransformer {
+	transformer := &ProjectTransformer{
+		ID:        p.ID,
+		Name:      p.Name,
+		CreatedAt: p.CreatedAt.Unix(),
+		UpdatedAt: p.UpdatedAt.Unix(),
+	}
+	if count != nil {
+		transformer.OpenTasks = count.Open
+		transformer.FinishedTasks = count.Finished
+	}
+
+	return transformer
+}
+
+type CreateProjectRequest struct {
+	Name string `json:"Name"`
+}
+
+func (c CreateProjectRequest) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+func (c *CreateProjectRequest) ToBase(owner uint64) *Project {
+	return &Project{
+		OwnerID: owner,
+		Name:    c.Name,
+	}
+}
+
+type UpdateProjectRequest struct {
+	Name string `json:"Name"`
+}
+
+func (u UpdateProjectRequest) Validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.Name, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+func (u *UpdateProjectRequest) ToBase(project *Project) *Project {
+	return &Project{
+		ID:        project.ID,
+		OwnerID:   project.OwnerID,
+		Name:      u.Name,
+		CreatedAt: project.CreatedAt,
+	}
+}
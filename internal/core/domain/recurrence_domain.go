@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/teambition/rrule-go"
+	"strings"
+	"time"
+)
+
+const (
+	// ScopeThis edits only one occurrence of a recurring task, the series goes on unchanged
+	ScopeThis = "this"
+	// ScopeAll edits the occurrence and all the future ones
+	ScopeAll = "all"
+)
+
+var (
+	ErrInvalidRecurrence = errors.New("recurrence must be a RRULE without DTSTART and with a FREQ of HOURLY or longer")
+	ErrInvalidScope      = errors.New("scope must be this or all")
+	ErrScopeRecurrence   = errors.New("recurrence can only be changed for all future occurrences")
+)
+
+// IsRecurring reports whether the task is an occurrence of a series that goes on
+func (t *Task) IsRecurring() bool {
+	return t.Recurrence != nil && t.RecurrenceStart != nil
+}
+
+// SetRecurrence changes the schedule of task, the series starts again from the task so
+// COUNT is counted from it. An empty rule stops the series.
+func (t *Task) SetRecurrence(rule *string) {
+	if rule == nil || *rule == "" {
+		t.StopRecurrence()
+		return
+	}
+
+	start := t.ActionTime
+	t.Recurrence = rule
+	t.RecurrenceStart = &start
+	t.HasNext = false
+}
+
+// StopRecurrence detaches task from its series, no occurrence follows it anymore
+func (t *Task) StopRecurrence() {
+	t.Recurrence = nil
+	t.RecurrenceStart = nil
+	t.HasNext = false
+}
+
+// NextOccurrence returns the occurrence following task with the same title and unfinished objectives,
+// missed occurrences before now are skipped. It returns nil when the series ended by its UNTIL or COUNT.
+// The occurrence is a top level task, an open sibling would keep the parent of a subtask from finishing.
+func (t *Task) NextOccurrence(now time.Time) (*Task, error) {
+	if !t.IsRecurring() {
+		return nil, nil
+	}
+
+	option, err := rrule.StrToROption(*t.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	option.Dtstart = t.RecurrenceStart.UTC()
+
+	rule, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, err
+	}
+
+	after := t.ActionTime
+	if now.After(after) {
+		after = now
+	}
+
+	actionTime := rule.After(after, false)
+	if actionTime.IsZero() {
+		return nil, nil
+	}
+
+	var objectives []*Objective
+	for _, obj := range t.Objective {
+		objectives = append(objectives, &Objective{
+			ObjectiveName: obj.ObjectiveName,
+			IsFinished:    false,
+		})
+	}
+
+	return &Task{
+		OwnerID:         t.OwnerID,
+		ProjectID:       t.ProjectID,
+		Title:           t.Title,
+		Priority:        t.Priority,
+		ActionTime:      actionTime.UTC(),
+		DueAt:           t.nextDue(actionTime.UTC()),
+		IsFinished:      false,
+		Version:         1,
+		Recurrence:      t.Recurrence,
+		RecurrenceStart: t.RecurrenceStart,
+		Objective:       objectives,
+		Tags:            t.Tags,
+	}, nil
+}
+
+// CarryOver returns next, the occurrence following task, with the changes made to task since before
+// carried over to it, or next itself when none has to be. A restarted series moves next to the
+// occurrence following task, nil is returned when no occurrence follows task anymore.
+func (t *Task) CarryOver(before *Task, next *Task, now time.Time) (*Task, error) {
+	if !t.IsRecurring() {
+		return nil, nil
+	}
+
+	carried := *next
+	carried.events = nil
+	changed := false
+
+	if t.Title != before.Title {
+		carried.Title, changed = t.Title, true
+	}
+	if t.Priority != before.Priority {
+		carried.Priority, changed = t.Priority, true
+	}
+	if !equalID(t.ProjectID, before.ProjectID) {
+		carried.ProjectID, changed = t.ProjectID, true
+	}
+	if !equalTags(t.Tags, before.Tags) {
+		carried.Tags, changed = t.Tags, true
+	}
+	if !equalDue(t, before) {
+		carried.DueAt, changed = t.nextDue(next.ActionTime), true
+	}
+	if t.Objective != nil && !equalObjectiveNames(t.Objective, before.Objective) {
+		carried.Objective, changed = t.carryObjectives(next), true
+		if len(carried.Objective) > 0 {
+			carried.IsFinished = carried.IsAllObjectivesFinished()
+		}
+	}
+
+	// a new rule or a moved occurrence restarted the series from task, next moves along with it
+	if !t.HasNext {
+		following, err := t.NextOccurrence(now)
+		if err != nil || following == nil {
+			return nil, err
+		}
+
+		carried.ActionTime = following.ActionTime
+		carried.DueAt = following.DueAt
+		carried.Recurrence = following.Recurrence
+		carried.RecurrenceStart = following.RecurrenceStart
+		changed = true
+	}
+
+	if !changed {
+		return next, nil
+	}
+
+	return &carried, nil
+}
+
+// carryObjectives lists the objectives of task for next, an objective of next with the same name
+// keeps its id & finished state
+func (t *Task) carryObjectives(next *Task) []*Objective {
+	objectives := []*Objective{}
+	for _, obj := range t.Objective {
+		carried := &Objective{ObjectiveName: obj.ObjectiveName}
+		for _, existing := range next.Objective {
+			if existing.ObjectiveName == obj.ObjectiveName {
+				carried.ID, carried.IsFinished = existing.ID, existing.IsFinished
+				break
+			}
+		}
+		objectives = append(objectives, carried)
+	}
+
+	return objectives
+}
+
+// nextDue keeps the time between the action time and the due date in the occurrence starting at actionTime
+func (t *Task) nextDue(actionTime time.Time) *time.Time {
+	if t.DueAt == nil {
+		return nil
+	}
+
+	dueAt := actionTime.Add(t.DueAt.Sub(t.ActionTime))
+	return &dueAt
+}
+
+// ChangesRecurrence reports whether the patched document has another rule than task
+func (p *PatchTaskRequest) ChangesRecurrence(task *Task) bool {
+	return !equalRecurrence(p.Recurrence, task.Recurrence)
+}
+
+// ChangesRecurrence reports whether the request has another rule than task, an omitted rule keeps it
+func (u *UpdateTaskRequest) ChangesRecurrence(task *Task) bool {
+	return u.Recurrence != nil && !equalRecurrence(u.Recurrence, task.Recurrence)
+}
+
+func equalRecurrence(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func equalID(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalDue reports whether a & b are due the same time after their action time
+func equalDue(a, b *Task) bool {
+	if a.DueAt == nil || b.DueAt == nil {
+		return a.DueAt == b.DueAt
+	}
+
+	return a.DueAt.Sub(a.ActionTime) == b.DueAt.Sub(b.ActionTime)
+}
+
+func equalObjectiveNames(a, b []*Objective) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ObjectiveName != b[i].ObjectiveName {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validRecurrence accepts an empty rule, it stops the series
+func validRecurrence(value interface{}) error {
+	value, isNil := validation.Indirect(value)
+	rule, _ := value.(string)
+	if isNil || rule == "" {
+		return nil
+	}
+
+	if strings.Contains(rule, "\n") || strings.Contains(strings.ToUpper(rule), "DTSTART") {
+		return ErrInvalidRecurrence
+	}
+
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return ErrInvalidRecurrence
+	}
+
+	if option.Freq == rrule.MINUTELY || option.Freq == rrule.SECONDLY {
+		return ErrInvalidRecurrence
+	}
+
+	return nil
+}
+
+// ValidScope accepts an empty scope, it defaults to all future occurrences
+func ValidScope(scope string) error {
+	return validation.Validate(scope, validation.In(ScopeThis, ScopeAll).Error(ErrInvalidScope.Error()))
+}
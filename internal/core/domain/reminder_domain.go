@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	ReminderPending = "pending"
+	ReminderSent    = "sent"
+	ReminderFailed  = "failed"
+)
+
+var ErrReminderExists = errors.New("reminder was already claimed")
+
+// Reminder is the delivery state of the reminder of a task, fired Offset before its ActionTime.
+// A reminder is claimed as pending before it is sent so the other workers don't send it too, a
+// moved ActionTime gets new reminders.
+type Reminder struct {
+	ID            uint64
+	TaskID        uint64
+	OffsetSeconds int64
+	ActionTime    time.Time
+	Status        string
+	Error         string
+	Attempts      int
+	// NextAttemptAt is when the reminder is attempted again unless it was sent, nil once it was
+	// sent or ran out of attempts
+	NextAttemptAt *time.Time
+	CreatedAt     time.Time
+	SentAt        *time.Time
+
+	Task *Task `gorm:"foreignKey:TaskID;references:ID"`
+}
+
+func NewReminder(task *Task, offset time.Duration) *Reminder {
+	return &Reminder{
+		TaskID:        task.ID,
+		OffsetSeconds: int64(offset / time.Second),
+		ActionTime:    task.ActionTime,
+		Status:        ReminderPending,
+		CreatedAt:     time.Now(),
+		Task:          task,
+	}
+}
+
+func (r *Reminder) Offset() time.Duration {
+	return time.Duration(r.OffsetSeconds) * time.Second
+}
+
+// Claimed records an attempt of the reminder, it is kept from the other workers until until and
+// attempted again from then when the attempt never finished
+func (r *Reminder) Claimed(until time.Time) {
+	r.Attempts++
+	r.Status = ReminderPending
+	r.NextAttemptAt = &until
+}
+
+// Sent marks the reminder as delivered, a failed delivery keeps its error and is retried after
+// backoff doubled for every previous attempt until maxAttempts is reached
+func (r *Reminder) Sent(err error, backoff time.Duration, maxAttempts int) {
+	now := time.Now()
+	r.NextAttemptAt = nil
+
+	if err != nil {
+		r.Status = ReminderFailed
+		r.Error = err.Error()
+		if r.Attempts < maxAttempts {
+			nextAttemptAt := now.Add(backoff * time.Duration(math.Pow(2, float64(r.Attempts-1))))
+			r.NextAttemptAt = &nextAttemptAt
+		}
+		return
+	}
+
+	r.Status = ReminderSent
+	r.Error = ""
+	r.SentAt = &now
+}
+
+// Outdated reports whether the task of the reminder was purged, trashed, finished or moved since the
+// reminder was claimed or already started at now, the reminder isn't sent anymore
+func (r *Reminder) Outdated(now time.Time) bool {
+	task := r.Task
+
+	return task == nil || task.DeletedAt != nil || task.IsFinished ||
+		!task.ActionTime.Equal(r.ActionTime) || !task.ActionTime.After(now)
+}
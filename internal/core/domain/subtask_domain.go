@@ -0,0 +1,64 @@
+package domain
+
+// MaxTaskDepth is the number of levels of a task tree, the top level task included
+const MaxTaskDepth = 5
+
+// SetParent moves the task under the parent task, a nil or 0 id moves it to the top level
+func (t *Task) SetParent(parentID *uint64) {
+	if parentID == nil || *parentID == 0 {
+		t.ParentID = nil
+		return
+	}
+
+	id := *parentID
+	t.ParentID = &id
+}
+
+// IsChildOf reports whether the task is a subtask of the task with the given id
+func (t *Task) IsChildOf(id uint64) bool {
+	return t.ParentID != nil && *t.ParentID == id
+}
+
+// RollUp is the finished state of a parent task, it is only finished once all its objectives and
+// subtasks are
+func (t *Task) RollUp(subtasks []*Task) bool {
+	return t.IsAllObjectivesFinished() && AllFinished(subtasks)
+}
+
+// AllFinished reports whether every task is finished
+func AllFinished(tasks []*Task) bool {
+	for _, task := range tasks {
+		if !task.IsFinished {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ToTaskTree transforms the task with its subtasks nested from its descendants, in any order. Subtasks
+// is only filled here, a leaf has none.
+func (t *Task) ToTaskTree(descendants []*Task) *TaskTransformer {
+	children := map[uint64][]*Task{}
+	for _, descendant := range descendants {
+		if descendant.ParentID != nil {
+			children[*descendant.ParentID] = append(children[*descendant.ParentID], descendant)
+		}
+	}
+
+	return t.toTaskTree(children)
+}
+
+func (t *Task) toTaskTree(children map[uint64][]*Task) *TaskTransformer {
+	tree := t.ToTaskTransformer()
+	for _, child := range children[t.ID] {
+		tree.Subtasks = append(tree.Subtasks, child.toTaskTree(children))
+	}
+
+	return tree
+}
+
+// MoveTaskRequest moves a task under another task, a null or 0 Parent_ID moves it to the top level
+type MoveTaskRequest struct {
+	ParentID *uint64 `json:"Parent_ID"`
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// TagModeAny lists the tasks having at least one of the tags, TagModeAll the ones having all of them
+	TagModeAny = "any"
+	TagModeAll = "all"
+
+	maxTaskTags = 20
+)
+
+var ErrInvalidTag = errors.New("tags must be 1 to 50 characters without commas")
+
+// Tag is a label of the tasks of its owner, it is created the first time a task is labelled with it
+type Tag struct {
+	ID        uint64
+	OwnerID   uint64
+	Name      string
+	CreatedAt time.Time
+}
+
+// TaskTag is a row of the join table of the tasks and their tags
+type TaskTag struct {
+	TaskID uint64
+	TagID  uint64
+}
+
+// TagCount is a tag and the number of tasks labelled with it, the trashed tasks aren't counted
+type TagCount struct {
+	Name       string `json:"Name"`
+	UsageCount int64  `json:"Usage_Count"`
+}
+
+// NormalizeTags returns the tags trimmed, lower cased, sorted and without duplicates, nil stays nil
+// so a missing list can be told apart from an empty one
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
+
+// HasTags reports whether the task has any of the tags, or all of them when all is set
+func (t *Task) HasTags(tags []string, all bool) bool {
+	for _, tag := range tags {
+		found := false
+		for _, own := range t.Tags {
+			if own == tag {
+				found = true
+				break
+			}
+		}
+
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+
+	return all
+}
+
+func validTags(value interface{}) error {
+	tags, _ := value.([]string)
+
+	if len(tags) > maxTaskTags {
+		return errors.New("a task can't have more than 20 tags")
+	}
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if err := validation.Validate(tag, validation.Required, validation.Length(1, 50)); err != nil || strings.Contains(tag, ",") {
+			return ErrInvalidTag
+		}
+	}
+
+	return nil
+}
@@ -1,20 +1,154 @@
 package domain
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"strconv"
+	"strings"
 	"time"
 )
 
+var (
+	ErrInvalidCursor       = errors.New("cursor is not valid")
+	ErrTaskVersionConflict = errors.New("task was changed since it was read")
+)
+
+const (
+	SortActionTime = "action_time"
+	SortCreatedAt  = "created_at"
+	SortUpdatedAt  = "updated_at"
+	SortTitle      = "title"
+	SortProgress   = "progress"
+	// SortUrgency orders by priority, the highest first, then by due date, the tasks without one last
+	SortUrgency = "urgency"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
 type Task struct {
 	ID         uint64
+	OwnerID    uint64
+	ProjectID  *uint64
+	ParentID   *uint64
 	Title      string
+	Priority   Priority
 	ActionTime time.Time
+	// DueAt is when the task must be finished by, ActionTime is when it is started
+	DueAt      *time.Time
 	IsFinished bool
+	Version    uint64
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+
+	// Recurrence is the RRULE of the series starting at RecurrenceStart, HasNext is set once the
+	// following occurrence was created and NextID is its id
+	Recurrence      *string
+	RecurrenceStart *time.Time
+	HasNext         bool
+	NextID          *uint64
+
+	// Rank & Highlights are only filled when searching
+	Rank       float64  `gorm:"column:search_rank;->"`
+	Highlights []string `gorm:"-"`
 
 	Objective []*Objective
+	// Tags are the sorted names of the tags of the task, stored in the task_tags join table
+	Tags []string `gorm:"-"`
+	// BlockedBy are the ids of the tasks blocking this one, stored in the task_dependencies table
+	BlockedBy []uint64 `gorm:"-"`
+
+	// events are the types of the outbox events recorded since the task was read
+	events []string
+}
+
+// Progress is the ratio of finished objectives, a task without objectives is either 0 or 1
+func (t *Task) Progress() float64 {
+	if len(t.Objective) == 0 {
+		if t.IsFinished {
+			return 1
+		}
+		return 0
+	}
+
+	var finished int
+	for _, objective := range t.Objective {
+		if objective.IsFinished {
+			finished++
+		}
+	}
+
+	return float64(finished) / float64(len(t.Objective))
+}
+
+// IsAllObjectivesFinished is the finished state of a task derived from its objectives
+func (t *Task) IsAllObjectivesFinished() bool {
+	for _, objective := range t.Objective {
+		if !objective.IsFinished {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetTags is never nil, so a task without tags is responded with an empty list
+func (t *Task) GetTags() []string {
+	if t.Tags == nil {
+		return []string{}
+	}
+
+	return t.Tags
+}
+
+// SetProject moves the task to the project, a nil or 0 id removes it from its project
+func (t *Task) SetProject(projectID *uint64) {
+	if projectID == nil || *projectID == 0 {
+		t.ProjectID = nil
+		return
+	}
+
+	id := *projectID
+	t.ProjectID = &id
+}
+
+// GetObjective returns the objective of the task with the given id, nil when not found
+func (t *Task) GetObjective(id uint64) *Objective {
+	for _, objective := range t.Objective {
+		if objective.ID == id {
+			return objective
+		}
+	}
+
+	return nil
+}
+
+// ETag is the strong entity tag of the current version of the task
+func (t *Task) ETag() string {
+	return versionETag(t.Version)
+}
+
+// MatchETag evaluates an If-Match header against the task, an empty header always matches
+func (t *Task) MatchETag(ifMatch string) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == t.ETag() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func versionETag(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
 }
 
 func (t *Task) GetObjectives() []ObjectiveTransformer {
@@ -29,30 +163,79 @@ func (t *Task) GetObjectives() []ObjectiveTransformer {
 
 type TaskTransformer struct {
 	ID         uint64                 `json:"Task_ID"`
+	ProjectID  *uint64                `json:"Project_ID"`
+	ParentID   *uint64                `json:"Parent_ID"`
 	Title      string                 `json:"Title"`
+	Priority   string                 `json:"Priority"`
 	ActionTime int64                  `json:"Action_Time"`
+	DueTime    *int64                 `json:"Due_Time"`
 	CreatedAt  int64                  `json:"Created_Time"`
 	UpdatedAt  int64                  `json:"Updated_Time"`
 	IsFinished bool                   `json:"Is_Finished"`
+	Version    uint64                 `json:"Version"`
+	DeletedAt  *int64                 `json:"Deleted_Time,omitempty"`
+	Recurrence *string                `json:"Recurrence,omitempty"`
 	Objectives []ObjectiveTransformer `json:"Objective_List"`
+	Tags       []string               `json:"Tags"`
+	BlockedBy  []uint64               `json:"Blocked_By"`
+	Rank       float64                `json:"Rank,omitempty"`
+	Highlights []string               `json:"Highlights,omitempty"`
+	Subtasks   []*TaskTransformer     `json:"Subtasks,omitempty"`
+}
+
+// ETag is the entity tag of the transformed task, same as Task.ETag
+func (t *TaskTransformer) ETag() string {
+	return versionETag(t.Version)
 }
 
 func (t *Task) ToTaskTransformer() *TaskTransformer {
+	var deletedAt *int64
+	if t.DeletedAt != nil {
+		unix := t.DeletedAt.Unix()
+		deletedAt = &unix
+	}
+
 	return &TaskTransformer{
 		ID:         t.ID,
+		ProjectID:  t.ProjectID,
+		ParentID:   t.ParentID,
 		Title:      t.Title,
+		Priority:   t.Priority.String(),
 		ActionTime: t.ActionTime.Unix(),
+		DueTime:    t.GetDueTime(),
 		CreatedAt:  t.CreatedAt.Unix(),
 		UpdatedAt:  t.UpdatedAt.Unix(),
 		IsFinished: t.IsFinished,
+		Version:    t.Version,
+		DeletedAt:  deletedAt,
+		Recurrence: t.Recurrence,
 		Objectives: t.GetObjectives(),
+		Tags:       t.GetTags(),
+		BlockedBy:  t.GetBlockedBy(),
+		Rank:       t.Rank,
+		Highlights: t.Highlights,
 	}
 }
 
 type CreateTaskRequst struct {
 	Title      string   `json:"Title"`
+	Priority   *string  `json:"Priority"`
 	ActionTime int64    `json:"Action_Time"`
+	DueTime    *int64   `json:"Due_Time"`
 	Objectives []string `json:"Objective_List"`
+	Recurrence *string  `json:"Recurrence"`
+	ProjectID  *uint64  `json:"Project_ID"`
+	ParentID   *uint64  `json:"Parent_ID"`
+	Tags       []string `json:"Tags"`
+}
+
+func (c CreateTaskRequst) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Priority, validation.In(PriorityNames...)),
+		validation.Field(&c.DueTime, validation.Min(int64(0))),
+		validation.Field(&c.Recurrence, validation.By(validRecurrence)),
+		validation.Field(&c.Tags, validation.By(validTags)),
+	)
 }
 
 func (c *CreateTaskRequst) ToBaseObjectives() []*Objective {
@@ -69,17 +252,48 @@ func (c *CreateTaskRequst) ToBaseObjectives() []*Objective {
 }
 
 func (c *CreateTaskRequst) ToBase() *Task {
-	return &Task{
+	task := &Task{
 		Title:      c.Title,
 		ActionTime: time.Unix(c.ActionTime, 0).UTC(),
 		IsFinished: false,
+		Version:    1,
 		Objective:  c.ToBaseObjectives(),
+		Tags:       NormalizeTags(c.Tags),
+	}
+	if c.Priority != nil {
+		task.Priority = ParsePriority(*c.Priority)
 	}
+	task.SetDue(c.DueTime)
+	task.SetRecurrence(c.Recurrence)
+	task.SetProject(c.ProjectID)
+	task.SetParent(c.ParentID)
+
+	return task
 }
 
 type UpdateTaskRequest struct {
 	Title      string                   `json:"title"`
 	Objectives []UpdateObjectiveRequest `json:"Objective_List"`
+	// Recurrence is kept when nil, an empty rule stops the series
+	Recurrence *string `json:"Recurrence"`
+	// ProjectID is kept when nil, 0 removes the task from its project
+	ProjectID *uint64 `json:"Project_ID"`
+	// Tags are kept when nil, an empty list removes them
+	Tags []string `json:"Tags"`
+	// Priority is kept when nil
+	Priority *string `json:"Priority"`
+	// DueTime is kept when nil, 0 removes the due date
+	DueTime *int64 `json:"Due_Time"`
+}
+
+func (u UpdateTaskRequest) Validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.Priority, validation.In(PriorityNames...)),
+		validation.Field(&u.DueTime, validation.Min(int64(0))),
+		validation.Field(&u.Objectives, validation.By(uniqueObjectiveIDs)),
+		validation.Field(&u.Recurrence, validation.By(validRecurrence)),
+		validation.Field(&u.Tags, validation.By(validTags)),
+	)
 }
 
 func (u *UpdateTaskRequest) ToBaseObjectives() ([]*Objective, bool) {
@@ -95,6 +309,7 @@ func (u *UpdateTaskRequest) ToBaseObjectives() ([]*Objective, bool) {
 		}
 
 		objectives = append(objectives, &Objective{
+			ID:            obj.ID,
 			ObjectiveName: obj.ObjectiveName,
 			IsFinished:    obj.IsFinished,
 		})
@@ -110,32 +325,270 @@ func (u *UpdateTaskRequest) ToBaseObjectives() ([]*Objective, bool) {
 func (u *UpdateTaskRequest) ToBase(task *Task) *Task {
 	objestives, isAllFinished := u.ToBaseObjectives()
 
-	return &Task{
-		ID:         task.ID,
-		Title:      u.Title,
-		ActionTime: task.ActionTime,
-		IsFinished: isAllFinished,
-		CreatedAt:  task.CreatedAt,
-		Objective:  objestives,
+	updated := &Task{
+		ID:              task.ID,
+		OwnerID:         task.OwnerID,
+		ProjectID:       task.ProjectID,
+		ParentID:        task.ParentID,
+		Title:           u.Title,
+		Priority:        task.Priority,
+		ActionTime:      task.ActionTime,
+		DueAt:           task.DueAt,
+		IsFinished:      isAllFinished,
+		Version:         task.Version,
+		CreatedAt:       task.CreatedAt,
+		Recurrence:      task.Recurrence,
+		RecurrenceStart: task.RecurrenceStart,
+		HasNext:         task.HasNext,
+		Objective:       objestives,
+		Tags:            task.Tags,
 	}
+	if u.Objectives == nil {
+		// the stored objectives are kept, so is the finished state derived from them
+		updated.IsFinished = task.IsFinished
+		if len(task.Objective) > 0 {
+			updated.IsFinished = task.IsAllObjectivesFinished()
+		}
+	}
+	if u.Tags != nil {
+		updated.Tags = NormalizeTags(u.Tags)
+	}
+	// an unchanged rule keeps the series going, a new one restarts it from this task
+	if u.ChangesRecurrence(task) {
+		updated.SetRecurrence(u.Recurrence)
+	}
+	if u.ProjectID != nil {
+		updated.SetProject(u.ProjectID)
+	}
+	if u.Priority != nil {
+		updated.Priority = ParsePriority(*u.Priority)
+	}
+	if u.DueTime != nil {
+		updated.SetDue(u.DueTime)
+	}
+
+	return updated
+}
+
+// PatchTaskRequest is the document of a task that JSON merge patches are applied to
+type PatchTaskRequest struct {
+	Title      string                   `json:"Title"`
+	Priority   string                   `json:"Priority"`
+	ActionTime int64                    `json:"Action_Time"`
+	DueTime    *int64                   `json:"Due_Time"`
+	Objectives []UpdateObjectiveRequest `json:"Objective_List"`
+	Recurrence *string                  `json:"Recurrence"`
+	ProjectID  *uint64                  `json:"Project_ID"`
+	Tags       []string                 `json:"Tags"`
+}
+
+func (t *Task) ToPatchTaskRequest() *PatchTaskRequest {
+	objectives := []UpdateObjectiveRequest{}
+
+	for _, obj := range t.Objective {
+		objectives = append(objectives, UpdateObjectiveRequest{
+			ID:            obj.ID,
+			ObjectiveName: obj.ObjectiveName,
+			IsFinished:    obj.IsFinished,
+		})
+	}
+
+	return &PatchTaskRequest{
+		Title:      t.Title,
+		Priority:   t.Priority.String(),
+		ActionTime: t.ActionTime.Unix(),
+		DueTime:    t.GetDueTime(),
+		Objectives: objectives,
+		Recurrence: t.Recurrence,
+		ProjectID:  t.ProjectID,
+		Tags:       t.GetTags(),
+	}
+}
+
+func (p PatchTaskRequest) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Title, validation.Required, validation.Length(1, 255)),
+		// a null priority is removed by the patch, which is no priority
+		validation.Field(&p.Priority, validation.In(PriorityNames...)),
+		validation.Field(&p.ActionTime, validation.Required),
+		validation.Field(&p.DueTime, validation.Min(int64(0))),
+		validation.Field(&p.Objectives, validation.Each(validation.By(validObjectiveName)), validation.By(uniqueObjectiveIDs)),
+		validation.Field(&p.Recurrence, validation.By(validRecurrence)),
+		validation.Field(&p.Tags, validation.By(validTags)),
+	)
+}
+
+func validObjectiveName(value interface{}) error {
+	objective, _ := value.(UpdateObjectiveRequest)
+
+	return validation.Validate(objective.ObjectiveName, validation.Required, validation.Length(1, 255))
+}
+
+// ToBase always returns the full objective list, so a patch removing every objective clears them
+func (p *PatchTaskRequest) ToBase(task *Task) *Task {
+	objectives := []*Objective{}
+
+	for _, obj := range p.Objectives {
+		objectives = append(objectives, &Objective{
+			ID:            obj.ID,
+			ObjectiveName: obj.ObjectiveName,
+			IsFinished:    obj.IsFinished,
+		})
+	}
+
+	patched := &Task{
+		ID:              task.ID,
+		OwnerID:         task.OwnerID,
+		ParentID:        task.ParentID,
+		Title:           p.Title,
+		Priority:        ParsePriority(p.Priority),
+		ActionTime:      time.Unix(p.ActionTime, 0).UTC(),
+		IsFinished:      task.IsFinished,
+		Version:         task.Version,
+		CreatedAt:       task.CreatedAt,
+		Recurrence:      task.Recurrence,
+		RecurrenceStart: task.RecurrenceStart,
+		HasNext:         task.HasNext,
+		Objective:       objectives,
+		// a null list removes the tags, same as an empty one
+		Tags: NormalizeTags(append([]string{}, p.Tags...)),
+	}
+	patched.SetProject(p.ProjectID)
+	patched.SetDue(p.DueTime)
+
+	// without objectives the finished state can't be derived, it is kept as is
+	if len(objectives) > 0 {
+		patched.IsFinished = patched.IsAllObjectivesFinished()
+	}
+
+	// a new rule or a moved occurrence restarts the series from this task
+	if !equalRecurrence(p.Recurrence, task.Recurrence) || !patched.ActionTime.Equal(task.ActionTime) {
+		patched.SetRecurrence(p.Recurrence)
+	}
+
+	return patched
 }
 
 type TaskParams struct {
-	Page            int     `query:"Page"`
-	Limit           int     `query:"Limit"`
-	Title           *string `query:"Title"`
-	ActionTimeStart *int    `query:"Action_Time_Start"`
-	ActionTimeEnd   *int    `query:"Action_Time_End"`
-	IsFinished      *bool   `query:"Is_Finished"`
+	Page            int      `query:"Page"`
+	Limit           int      `query:"Limit"`
+	Title           *string  `query:"Title"`
+	Q               *string  `query:"Q"`
+	ActionTimeStart *int     `query:"Action_Time_Start"`
+	ActionTimeEnd   *int     `query:"Action_Time_End"`
+	IsFinished      *bool    `query:"Is_Finished"`
+	Priority        []string `query:"Priority"`
+	DueTimeStart    *int     `query:"Due_Time_Start"`
+	DueTimeEnd      *int     `query:"Due_Time_End"`
+	ProjectID       *uint64  `query:"Project_ID"`
+	Tags            []string `query:"Tags"`
+	TagMode         string   `query:"Tag_Mode"`
+	IsBlocked       *bool    `query:"Is_Blocked"`
+	Cursor          *string  `query:"Cursor"`
+	Sort            []string `query:"Sort"`
+	Order           []string `query:"Order"`
+
+	// Trashed lists the soft deleted tasks instead of the active ones, it is set by the trash endpoint
+	Trashed bool `query:"-"`
 }
 
 func (t TaskParams) Validate() error {
 	return validation.ValidateStruct(&t,
-		validation.Field(&t.Page, validation.Required, validation.By(moreThanNol)),
+		validation.Field(&t.Page, validation.When(t.Cursor == nil, validation.Required), validation.By(moreThanNol)),
 		validation.Field(&t.Limit, validation.Required, validation.By(moreThanNol)),
+		validation.Field(&t.Q, validation.NilOrNotEmpty),
+		validation.Field(&t.Priority, validation.Each(validation.In(PriorityNames...))),
+		validation.Field(&t.DueTimeEnd, validation.By(t.notBeforeDueTimeStart)),
+		validation.Field(&t.TagMode, validation.In(TagModeAny, TagModeAll)),
+		validation.Field(&t.Cursor, validation.By(validCursor)),
+		validation.Field(&t.Sort,
+			validation.When(t.Cursor != nil, validation.Empty.Error("cannot be used with cursor")),
+			validation.Each(validation.In(SortActionTime, SortCreatedAt, SortUpdatedAt, SortTitle, SortProgress, SortUrgency)),
+		),
+		validation.Field(&t.Order,
+			validation.Length(0, len(t.Sort)),
+			validation.Each(validation.In(OrderAsc, OrderDesc)),
+		),
 	)
 }
 
+func (t TaskParams) notBeforeDueTimeStart(value interface{}) error {
+	end, _ := value.(*int)
+	if end == nil || t.DueTimeStart == nil || *end >= *t.DueTimeStart {
+		return nil
+	}
+
+	return errors.New("must not be before Due_Time_Start")
+}
+
+// TaskSort is one key of the task ordering
+type TaskSort struct {
+	Field string
+	Desc  bool
+}
+
+// GetSorts pairs every Sort with its Order, missing orders are ascending
+func (t *TaskParams) GetSorts() []TaskSort {
+	var sorts []TaskSort
+
+	for i, field := range t.Sort {
+		sorts = append(sorts, TaskSort{
+			Field: field,
+			Desc:  i < len(t.Order) && t.Order[i] == OrderDesc,
+		})
+	}
+
+	return sorts
+}
+
+func validCursor(value interface{}) error {
+	cursor, _ := value.(*string)
+	if cursor == nil {
+		return nil
+	}
+
+	_, err := DecodeTaskCursor(*cursor)
+	return err
+}
+
+// TaskCursor is the position of the last task of a page when paginating by (action_time, id)
+type TaskCursor struct {
+	ActionTime time.Time `json:"t"`
+	ID         uint64    `json:"i"`
+}
+
+func (t *Task) ToTaskCursor() *TaskCursor {
+	return &TaskCursor{
+		ActionTime: t.ActionTime,
+		ID:         t.ID,
+	}
+}
+
+// Encode returns the cursor as an opaque string for clients
+func (c *TaskCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeTaskCursor parses a cursor made by Encode, an empty value is the first page
+func DecodeTaskCursor(value string) (*TaskCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := new(TaskCursor)
+	if err := json.Unmarshal(raw, cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
 func moreThanNol(value interface{}) error {
 	intValue, _ := value.(int)
 
@@ -148,5 +601,6 @@ func moreThanNol(value interface{}) error {
 
 type TaskPagination struct {
 	ListData       []*TaskTransformer `json:"List_Data"`
-	PaginationData Pagination         `json:"Pagination_Data"`
+	PaginationData *Pagination        `json:"Pagination_Data,omitempty"`
+	NextCursor     *string            `json:"Next_Cursor,omitempty"`
 }
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// OwnerKey is the context key of the id of the user the request is made by
+	OwnerKey = "owner_id"
+	// SystemKey is the context key marking the work made for every user, such as the background jobs
+	SystemKey = "system"
+
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+var (
+	ErrInvalidToken = errors.New("token is not valid")
+	ErrEmailTaken   = errors.New("email is already registered")
+
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// OwnerFromContext is the user set on ctx
+func OwnerFromContext(ctx context.Context) (uint64, bool) {
+	owner, ok := ctx.Value(OwnerKey).(uint64)
+	return owner, ok
+}
+
+// WithOwner returns ctx made by owner, for the work outliving the context of its request
+func WithOwner(ctx context.Context, owner uint64) context.Context {
+	return context.WithValue(ctx, OwnerKey, owner)
+}
+
+// WithSystem returns ctx made for every user, the background jobs see the rows of all the users
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, SystemKey, true)
+}
+
+// IsSystem reports whether ctx was made for every user by WithSystem
+func IsSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(SystemKey).(bool)
+	return system
+}
+
+// OwnedBy reports whether the user set on ctx may see a row of owner, a ctx without a user only sees
+// the rows when it was made by WithSystem
+func OwnedBy(ctx context.Context, owner uint64) bool {
+	if current, ok := OwnerFromContext(ctx); ok {
+		return current == owner
+	}
+
+	return IsSystem(ctx)
+}
+
+type User struct {
+	ID           uint64
+	Email        string
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type UserTransformer struct {
+	ID        uint64 `json:"User_ID"`
+	Email     string `json:"Email"`
+	CreatedAt int64  `json:"Created_Time"`
+}
+
+func (u *User) ToUserTransformer() *UserTransformer {
+	return &UserTransformer{
+		ID:        u.ID,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt.Unix(),
+	}
+}
+
+// TokenTransformer is the pair of tokens returned on login, Expires_In is the lifetime of the
+// access token in seconds
+type TokenTransformer struct {
+	AccessToken  string `json:"Access_Token"`
+	RefreshToken string `json:"Refresh_Token"`
+	TokenType    string `json:"Token_Type"`
+	ExpiresIn    int64  `json:"Expires_In"`
+}
+
+// RegisterRequest refuses passwords longer than 72 bytes since bcrypt ignores the rest
+type RegisterRequest struct {
+	Email    string `json:"Email"`
+	Password string `json:"Password"`
+}
+
+func (r RegisterRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Email, validation.Required, validation.Length(3, 255), validation.Match(emailPattern).Error("must be a valid email address")),
+		validation.Field(&r.Password, validation.Required, validation.Length(8, 72)),
+	)
+}
+
+// GetEmail is the email compared case-insensitively
+func (r *RegisterRequest) GetEmail() string {
+	return strings.ToLower(strings.TrimSpace(r.Email))
+}
+
+type LoginRequest struct {
+	Email    string `json:"Email"`
+	Password string `json:"Password"`
+}
+
+func (l LoginRequest) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Email, validation.Required),
+		validation.Field(&l.Password, validation.Required),
+	)
+}
+
+func (l *LoginRequest) GetEmail() string {
+	return strings.ToLower(strings.TrimSpace(l.Email))
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"Refresh_Token"`
+}
+
+func (r RefreshRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.RefreshToken, validation.Required),
+	)
+}
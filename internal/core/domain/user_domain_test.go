@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOwnedBy(t *testing.T) {
+	owner := WithOwner(context.Background(), 1)
+	assert.True(t, OwnedBy(owner, 1))
+	assert.False(t, OwnedBy(owner, 2))
+
+	// only the work made for every user sees the rows without a user on ctx
+	assert.True(t, OwnedBy(WithSystem(context.Background()), 2))
+	assert.False(t, OwnedBy(context.Background(), 0))
+	assert.False(t, OwnedBy(context.Background(), 2))
+}
@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	EventTaskCreated  = "task.created"
+	EventTaskUpdated  = "task.updated"
+	EventTaskFinished = "task.finished"
+	EventTaskDeleted  = "task.deleted"
+
+	DeliveryPending = "pending"
+	DeliverySuccess = "success"
+	DeliveryFailed  = "failed"
+)
+
+var (
+	TaskEvents = []interface{}{EventTaskCreated, EventTaskUpdated, EventTaskFinished, EventTaskDeleted}
+
+	ErrInvalidWebhookURL = errors.New("must be an absolute http or https url")
+	ErrDeliveryClaimed   = errors.New("delivery was already claimed")
+)
+
+// TaskEvent is the payload delivered to the webhooks subscribed to its type
+type TaskEvent struct {
+	Type       string           `json:"Event"`
+	OccurredAt int64            `json:"Occurred_Time"`
+	Task       *TaskTransformer `json:"Task"`
+}
+
+func NewTaskEvent(eventType string, task *Task) *TaskEvent {
+	return &TaskEvent{
+		Type:       eventType,
+		OccurredAt: time.Now().Unix(),
+		Task:       task.ToTaskTransformer(),
+	}
+}
+
+// Webhook is a subscription of an url to task events, Events is stored comma separated
+type Webhook struct {
+	ID        uint64
+	OwnerID   uint64
+	URL       string
+	Secret    string
+	Events    string
+	IsActive  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Subscribes reports whether the webhook is active and subscribed to the event, a webhook only
+// receives the events of the tasks of its owner
+func (w *Webhook) Subscribes(event *OutboxEvent) bool {
+	if !w.IsActive || w.OwnerID != event.OwnerID {
+		return false
+	}
+
+	for _, eventType := range w.GetEvents() {
+		if eventType == event.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *Webhook) GetEvents() []string {
+	if w.Events == "" {
+		return []string{}
+	}
+
+	return strings.Split(w.Events, ",")
+}
+
+// Sign is the hex HMAC-SHA256 of payload keyed by the secret of the webhook
+func (w *Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type WebhookTransformer struct {
+	ID        uint64   `json:"Webhook_ID"`
+	URL       string   `json:"URL"`
+	Events    []string `json:"Events"`
+	IsActive  bool     `json:"Is_Active"`
+	CreatedAt int64    `json:"Created_Time"`
+	UpdatedAt int64    `json:"Updated_Time"`
+	// Secret is only returned once, when the webhook is created
+	Secret string `json:"Secret,omitempty"`
+}
+
+func (w *Webhook) ToWebhookTransformer() *WebhookTransformer {
+	return &WebhookTransformer{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.GetEvents(),
+		IsActive:  w.IsActive,
+		CreatedAt: w.CreatedAt.Unix(),
+		UpdatedAt: w.UpdatedAt.Unix(),
+	}
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"URL"`
+	Secret string   `json:"Secret"`
+	Events []string `json:"Events"`
+}
+
+func (c CreateWebhookRequest) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.URL, validation.Required, validation.Length(1, 2048), validation.By(validWebhookURL)),
+		validation.Field(&c.Secret, validation.Length(16, 255)),
+		validation.Field(&c.Events, validation.Required, validation.Each(validation.In(TaskEvents...))),
+	)
+}
+
+// ToBase generates a secret when none was given
+func (c *CreateWebhookRequest) ToBase() (*Webhook, error) {
+	secret := c.Secret
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	return &Webhook{
+		URL:      c.URL,
+		Secret:   secret,
+		Events:   strings.Join(c.Events, ","),
+		IsActive: true,
+	}, nil
+}
+
+type UpdateWebhookRequest struct {
+	URL    string   `json:"URL"`
+	Events []string `json:"Events"`
+	// IsActive is kept when nil
+	IsActive *bool `json:"Is_Active"`
+}
+
+func (u UpdateWebhookRequest) Validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.URL, validation.Required, validation.Length(1, 2048), validation.By(validWebhookURL)),
+		validation.Field(&u.Events, validation.Required, validation.Each(validation.In(TaskEvents...))),
+	)
+}
+
+func (u *UpdateWebhookRequest) ToBase(webhook *Webhook) *Webhook {
+	updated := &Webhook{
+		ID:        webhook.ID,
+		OwnerID:   webhook.OwnerID,
+		URL:       u.URL,
+		Secret:    webhook.Secret,
+		Events:    strings.Join(u.Events, ","),
+		IsActive:  webhook.IsActive,
+		CreatedAt: webhook.CreatedAt,
+	}
+	if u.IsActive != nil {
+		updated.IsActive = *u.IsActive
+	}
+
+	return updated
+}
+
+func validWebhookURL(value interface{}) error {
+	raw, _ := value.(string)
+
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	return nil
+}
+
+// WebhookDelivery is one event sent to one webhook, retried with an exponential backoff until
+// it succeeds or runs out of attempts. There is at most one delivery of an outbox event per webhook.
+type WebhookDelivery struct {
+	ID             uint64
+	WebhookID      uint64
+	EventID        uint64
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	Error          string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+
+	Webhook *Webhook `gorm:"foreignKey:WebhookID;references:ID"`
+}
+
+func NewWebhookDelivery(webhook *Webhook, event *OutboxEvent) *WebhookDelivery {
+	now := time.Now().UTC()
+
+	return &WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       event.ID,
+		Event:         event.Type,
+		Payload:       event.Payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
+
+// Attempted records the outcome of one attempt, a failed attempt is retried after backoff doubled
+// for every previous attempt until maxAttempts is reached
+func (d *WebhookDelivery) Attempted(responseStatus int, err error, backoff time.Duration, maxAttempts int) {
+	now := time.Now().UTC()
+
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	d.Error = ""
+
+	if err == nil && responseStatus >= 200 && responseStatus < 300 {
+		d.Status = DeliverySuccess
+		d.DeliveredAt = &now
+		return
+	}
+
+	if err != nil {
+		d.Error = err.Error()
+	}
+
+	if d.Attempts >= maxAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+
+	d.NextAttemptAt = now.Add(backoff * time.Duration(math.Pow(2, float64(d.Attempts-1))))
+}
+
+type WebhookDeliveryTransformer struct {
+	ID             uint64 `json:"Delivery_ID"`
+	Event          string `json:"Event"`
+	Status         string `json:"Status"`
+	Attempts       int    `json:"Attempts"`
+	ResponseStatus int    `json:"Response_Status"`
+	Error          string `json:"Error"`
+	NextAttemptAt  int64  `json:"Next_Attempt_Time"`
+	CreatedAt      int64  `json:"Created_Time"`
+	DeliveredAt    *int64 `json:"Delivered_Time"`
+}
+
+func (d *WebhookDelivery) ToWebhookDeliveryTransformer() *WebhookDeliveryTransformer {
+	var deliveredAt *int64
+	if d.DeliveredAt != nil {
+		unix := d.DeliveredAt.Unix()
+		deliveredAt = &unix
+	}
+
+	return &WebhookDeliveryTransformer{
+		ID:             d.ID,
+		Event:          d.Event,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		Error:          d.Error,
+		NextAttemptAt:  d.NextAttemptAt.Unix(),
+		CreatedAt:      d.CreatedAt.Unix(),
+		DeliveredAt:    deliveredAt,
+	}
+}
+
+type WebhookDeliveryParams struct {
+	Page  int `query:"Page"`
+	Limit int `query:"Limit"`
+}
+
+func (p WebhookDeliveryParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Page, validation.Required, validation.By(moreThanNol)),
+		validation.Field(&p.Limit, validation.Required, validation.By(moreThanNol)),
+	)
+}
+
+type WebhookDeliveryPagination struct {
+	ListData       []*WebhookDeliveryTransformer `json:"List_Data"`
+	PaginationData *Pagination                   `json:"Pagination_Data"`
+}
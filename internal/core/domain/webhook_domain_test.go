@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWebhookSign(t *testing.T) {
+	// test case 2 of RFC 4231
+	webhook := &Webhook{Secret: "Jefe"}
+
+	assert.Equal(t, "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		webhook.Sign([]byte("what do ya want for nothing?")))
+	assert.NotEqual(t, webhook.Sign([]byte("payload")), (&Webhook{Secret: "other"}).Sign([]byte("payload")))
+}
+
+func TestWebhookDeliveryAttempted(t *testing.T) {
+	delivery := &WebhookDelivery{Status: DeliveryPending}
+
+	// every failed attempt waits twice as long as the previous one
+	for attempt, wait := range []time.Duration{time.Minute, 2 * time.Minute} {
+		before := time.Now().UTC()
+		delivery.Attempted(0, errors.New("connection refused"), time.Minute, 3)
+
+		assert.Equal(t, attempt+1, delivery.Attempts)
+		assert.Equal(t, DeliveryPending, delivery.Status)
+		assert.Equal(t, "connection refused", delivery.Error)
+		assert.WithinDuration(t, before.Add(wait), delivery.NextAttemptAt, time.Second)
+	}
+
+	// a response out of 2xx is a failure too, the last one gives the delivery up
+	delivery.Attempted(500, nil, time.Minute, 3)
+	assert.Equal(t, DeliveryFailed, delivery.Status)
+	assert.Equal(t, 500, delivery.ResponseStatus)
+	assert.Empty(t, delivery.Error)
+	assert.Nil(t, delivery.DeliveredAt)
+}
+
+func TestWebhookDeliveryAttemptedSuccess(t *testing.T) {
+	delivery := &WebhookDelivery{Status: DeliveryPending, Attempts: 1, Error: "timeout"}
+
+	delivery.Attempted(204, nil, time.Minute, 3)
+
+	assert.Equal(t, DeliverySuccess, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Empty(t, delivery.Error)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
@@ -5,8 +5,8 @@ package mocks
 import (
 	context "context"
 
-	domain "github.com/pasarin-tech/base-go/internal/core/domain"
 	mock "github.com/stretchr/testify/mock"
+	domain "github.com/todo-list/internal/core/domain"
 )
 
 // EventService is an autogenerated mock type for the EventService type
@@ -14,13 +14,13 @@ type EventService struct {
 	mock.Mock
 }
 
-// CreateEvent provides a mock function with given fields: ctx, gtm
-func (_m *EventService) CreateEvent(ctx context.Context, gtm *domain.EngagementRequest) error {
-	ret := _m.Called(ctx, gtm)
+// CreateEvent provides a mock function with given fields: ctx, event
+func (_m *EventService) CreateEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	ret := _m.Called(ctx, event)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.EngagementRequest) error); ok {
-		r0 = rf(ctx, gtm)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxEvent) error); ok {
+		r0 = rf(ctx, event)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+)
+
+type (
+	ReminderNotifier interface {
+		Notify(ctx context.Context, reminder *domain.Reminder) error
+	}
+)
+
+type (
+	// WebhookSender posts the payload of delivery to webhook and returns the response status
+	WebhookSender interface {
+		Send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error)
+	}
+)
+
+type (
+	// EventPublisher publishes an outbox event to the consumers outside the application
+	EventPublisher interface {
+		Publish(ctx context.Context, event *domain.OutboxEvent) error
+	}
+)
@@ -3,14 +3,86 @@ package ports
 import (
 	"context"
 	"github.com/todo-list/internal/core/domain"
+	"time"
 )
 
 type (
 	TaskRepository interface {
 		Create(ctx context.Context, task *domain.Task) error
 		Update(ctx context.Context, task *domain.Task) error
-		Delete(ctx context.Context, id string) error
+		Delete(ctx context.Context, task *domain.Task) error
+		Restore(ctx context.Context, task *domain.Task) error
+		Purge(ctx context.Context, task *domain.Task) error
+		SaveOccurrence(ctx context.Context, task *domain.Task, next *domain.Task) error
 		GetOneByID(ctx context.Context, id string) (*domain.Task, error)
+		GetTrashedByID(ctx context.Context, id string) (*domain.Task, error)
+		GetAllAuditLogWithPaginate(ctx context.Context, id string, params *domain.AuditLogParams) ([]*domain.AuditLog, int64, error)
 		GetAllWithPaginate(ctx context.Context, params *domain.TaskParams) ([]*domain.Task, int64, error)
+		GetAllWithCursor(ctx context.Context, params *domain.TaskParams, cursor *domain.TaskCursor) ([]*domain.Task, error)
+		CountByProject(ctx context.Context, projectIDs []uint64) (map[uint64]*domain.ProjectCount, error)
+		GetAllTags(ctx context.Context) ([]*domain.TagCount, error)
+		GetAllByParent(ctx context.Context, parentIDs []uint64) ([]*domain.Task, error)
+		CreateDependency(ctx context.Context, dependency *domain.TaskDependency) error
+		DeleteDependency(ctx context.Context, dependency *domain.TaskDependency) error
+		GetAllBlockers(ctx context.Context, taskIDs []uint64) ([]*domain.Task, error)
+		GetAllBlockedIDs(ctx context.Context, blockerID uint64) ([]uint64, error)
+		// LockDependencies holds the dependency changes of the other transactions until the transaction
+		// of the repository is over
+		LockDependencies(ctx context.Context) error
+		GetAllDueRecurring(ctx context.Context, now time.Time, limit int) ([]*domain.Task, error)
+		GetAllDueReminders(ctx context.Context, now time.Time, offset time.Duration, limit int) ([]*domain.Task, error)
+		CreateReminder(ctx context.Context, reminder *domain.Reminder) error
+		GetAllRetryReminders(ctx context.Context, now time.Time, limit int) ([]*domain.Reminder, error)
+		ClaimReminder(ctx context.Context, reminder *domain.Reminder, now time.Time) error
+		UpdateReminder(ctx context.Context, reminder *domain.Reminder) error
+		CreateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error
+		UpdateObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error
+		DeleteObjective(ctx context.Context, task *domain.Task, objective *domain.Objective) error
+		// Transaction runs fn with a repository whose writes are committed when fn returns nil and
+		// rolled back when it returns an error, a transaction started on that repository is nested
+		Transaction(ctx context.Context, fn func(repo TaskRepository) error) error
+	}
+
+	ProjectRepository interface {
+		Create(ctx context.Context, project *domain.Project) error
+		Update(ctx context.Context, project *domain.Project) error
+		Delete(ctx context.Context, project *domain.Project) error
+		GetOneByID(ctx context.Context, id string) (*domain.Project, error)
+		GetAll(ctx context.Context) ([]*domain.Project, error)
+	}
+
+	WebhookRepository interface {
+		Create(ctx context.Context, webhook *domain.Webhook) error
+		Update(ctx context.Context, webhook *domain.Webhook) error
+		Delete(ctx context.Context, webhook *domain.Webhook) error
+		GetOneByID(ctx context.Context, id string) (*domain.Webhook, error)
+		GetAll(ctx context.Context) ([]*domain.Webhook, error)
+		CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error
+		ClaimDelivery(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time, until time.Time) error
+		UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+		GetAllDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error)
+		GetAllDeliveriesWithPaginate(ctx context.Context, webhook *domain.Webhook, params *domain.WebhookDeliveryParams) ([]*domain.WebhookDelivery, int64, error)
+	}
+
+	UserRepository interface {
+		Create(ctx context.Context, user *domain.User) error
+		GetOneByID(ctx context.Context, id uint64) (*domain.User, error)
+		GetOneByEmail(ctx context.Context, email string) (*domain.User, error)
+	}
+
+	APIKeyRepository interface {
+		Create(ctx context.Context, apiKey *domain.APIKey) error
+		Delete(ctx context.Context, apiKey *domain.APIKey) error
+		Touch(ctx context.Context, apiKey *domain.APIKey) error
+		GetOneByID(ctx context.Context, id string) (*domain.APIKey, error)
+		GetOneByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+		GetAll(ctx context.Context) ([]*domain.APIKey, error)
+	}
+
+	OutboxRepository interface {
+		CreateEvents(ctx context.Context, events []*domain.OutboxEvent) error
+		ClaimEvent(ctx context.Context, event *domain.OutboxEvent, now time.Time, until time.Time) error
+		UpdateEvent(ctx context.Context, event *domain.OutboxEvent) error
+		GetAllUnpublished(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEvent, error)
 	}
 )
@@ -3,14 +3,79 @@ package ports
 import (
 	"context"
 	"github.com/todo-list/internal/core/domain"
+	"io"
+	"time"
 )
 
 type (
 	TaskService interface {
 		Create(ctx context.Context, request *domain.CreateTaskRequst) error
-		Update(ctx context.Context, id string, request *domain.UpdateTaskRequest) error
-		Delete(ctx context.Context, id string) error
+		Update(ctx context.Context, id string, request *domain.UpdateTaskRequest, ifMatch string, scope string) error
+		Patch(ctx context.Context, id string, patch []byte, ifMatch string, scope string) error
+		Delete(ctx context.Context, id string, ifMatch string) error
 		GetOneByID(ctx context.Context, id string) (*domain.TaskTransformer, error)
 		GetAllWithPaginate(ctx context.Context, params *domain.TaskParams) (*domain.TaskPagination, error)
+		AddObjective(ctx context.Context, id string, request *domain.CreateObjectiveRequest, ifMatch string) error
+		RenameObjective(ctx context.Context, id string, objectiveID string, request *domain.RenameObjectiveRequest, ifMatch string) error
+		ToggleObjective(ctx context.Context, id string, objectiveID string, ifMatch string) error
+		DeleteObjective(ctx context.Context, id string, objectiveID string, ifMatch string) error
+		GetTrash(ctx context.Context, params *domain.TaskParams) (*domain.TaskPagination, error)
+		Restore(ctx context.Context, id string, ifMatch string) error
+		Purge(ctx context.Context, id string, ifMatch string) error
+		GetHistory(ctx context.Context, id string, params *domain.AuditLogParams) (*domain.AuditLogPagination, error)
+		GetTags(ctx context.Context) ([]*domain.TagCount, error)
+		GetSubtree(ctx context.Context, id string) (*domain.TaskTransformer, error)
+		Move(ctx context.Context, id string, request *domain.MoveTaskRequest, ifMatch string) error
+		AddBlocker(ctx context.Context, id string, blockerID string) error
+		RemoveBlocker(ctx context.Context, id string, blockerID string) error
+		Batch(ctx context.Context, request *domain.BatchRequest) (*domain.BatchTransformer, error)
+		Export(ctx context.Context, params *domain.TaskParams, format string, w io.Writer) error
+		Import(ctx context.Context, format string, file io.Reader) (*domain.ImportTransformer, error)
+		MaterializeDue(ctx context.Context, now time.Time) error
+	}
+
+	AuthService interface {
+		Register(ctx context.Context, request *domain.RegisterRequest) (*domain.UserTransformer, error)
+		Login(ctx context.Context, request *domain.LoginRequest) (*domain.TokenTransformer, error)
+		Refresh(ctx context.Context, request *domain.RefreshRequest) (*domain.TokenTransformer, error)
+		Authenticate(ctx context.Context, token string) (*domain.User, error)
+		AuthenticateKey(ctx context.Context, key string) (*domain.APIKey, error)
+	}
+
+	APIKeyService interface {
+		Create(ctx context.Context, request *domain.CreateAPIKeyRequest) (*domain.APIKeyTransformer, error)
+		Delete(ctx context.Context, id string) error
+		GetAll(ctx context.Context) ([]*domain.APIKeyTransformer, error)
+	}
+
+	ProjectService interface {
+		Create(ctx context.Context, request *domain.CreateProjectRequest) (*domain.ProjectTransformer, error)
+		Update(ctx context.Context, id string, request *domain.UpdateProjectRequest) error
+		Delete(ctx context.Context, id string) error
+		GetOneByID(ctx context.Context, id string) (*domain.ProjectTransformer, error)
+		GetAll(ctx context.Context) ([]*domain.ProjectTransformer, error)
+	}
+
+	ReminderService interface {
+		SendDue(ctx context.Context, now time.Time) error
+	}
+
+	WebhookService interface {
+		Create(ctx context.Context, request *domain.CreateWebhookRequest) (*domain.WebhookTransformer, error)
+		Update(ctx context.Context, id string, request *domain.UpdateWebhookRequest) error
+		Delete(ctx context.Context, id string) error
+		GetOneByID(ctx context.Context, id string) (*domain.WebhookTransformer, error)
+		GetAll(ctx context.Context) ([]*domain.WebhookTransformer, error)
+		GetDeliveries(ctx context.Context, id string, params *domain.WebhookDeliveryParams) (*domain.WebhookDeliveryPagination, error)
+		DeliverDue(ctx context.Context, now time.Time) error
+	}
+
+	// EventService consumes the events relayed from the outbox inside the application
+	EventService interface {
+		CreateEvent(ctx context.Context, event *domain.OutboxEvent) error
+	}
+
+	OutboxService interface {
+		Relay(ctx context.Context, now time.Time) error
 	}
 )
@@ -0,0 +1,90 @@
+package authsvc
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToCreateAPIKey = "Failed to create new API key"
+	FailedToGetAPIKey    = "Failed to get API key"
+	FailedToDeleteAPIKey = "Failed to revoke API key"
+	APIKeyNotFound       = "API key not found"
+)
+
+type apiKeyService struct {
+	log        *zap.Logger
+	apiKeyRepo ports.APIKeyRepository
+}
+
+func NewAPIKeyService(log *zap.Logger, apiKeyRepo ports.APIKeyRepository) ports.APIKeyService {
+	return &apiKeyService{
+		log:        log,
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Create returns the key with the plain key, it is the only time the key is returned
+func (instance *apiKeyService) Create(ctx context.Context, request *domain.CreateAPIKeyRequest) (*domain.APIKeyTransformer, error) {
+	owner, _ := domain.OwnerFromContext(ctx)
+
+	apiKey, key, err := request.ToBase(owner)
+	if err != nil {
+		instance.log.Error("failed to generate API key : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToCreateAPIKey)
+	}
+
+	if err := instance.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		instance.log.Error("failed to create API key : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToCreateAPIKey)
+	}
+
+	transformer := apiKey.ToAPIKeyTransformer()
+	transformer.Key = key
+
+	return transformer, nil
+}
+
+// Delete revokes the key, the requests made with it are refused right away
+func (instance *apiKeyService) Delete(ctx context.Context, id string) error {
+	_, err := strconv.Atoi(id)
+	if id == "" || err != nil {
+		return responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	apiKey, err := instance.apiKeyRepo.GetOneByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get API key by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToGetAPIKey)
+	}
+
+	if apiKey == nil {
+		return responseErr.ResponseNotFound(APIKeyNotFound)
+	}
+
+	if err := instance.apiKeyRepo.Delete(ctx, apiKey); err != nil {
+		instance.log.Error("failed to delete API key by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToDeleteAPIKey)
+	}
+
+	return nil
+}
+
+func (instance *apiKeyService) GetAll(ctx context.Context) ([]*domain.APIKeyTransformer, error) {
+	apiKeys, err := instance.apiKeyRepo.GetAll(ctx)
+	if err != nil {
+		instance.log.Error("failed to get API keys : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetAPIKey)
+	}
+
+	datas := []*domain.APIKeyTransformer{}
+	for _, apiKey := range apiKeys {
+		datas = append(datas, apiKey.ToAPIKeyTransformer())
+	}
+
+	return datas, nil
+}
@@ -0,0 +1,164 @@
+package authsvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"time"
+)
+
+var (
+	FailedToRegister       = "Failed to register user"
+	FailedToLogin          = "Failed to login"
+	EmailAlreadyRegistered = "Email is already registered"
+	InvalidCredentials     = "Email or password is wrong"
+	InvalidToken           = "Token is invalid or expired"
+	InvalidAPIKey          = "API key is invalid, revoked or expired"
+)
+
+type authService struct {
+	log        *zap.Logger
+	userRepo   ports.UserRepository
+	apiKeyRepo ports.APIKeyRepository
+	tokens     Tokens
+}
+
+func NewAuthService(log *zap.Logger, userRepo ports.UserRepository, apiKeyRepo ports.APIKeyRepository, tokens Tokens) ports.AuthService {
+	return &authService{
+		log:        log,
+		userRepo:   userRepo,
+		apiKeyRepo: apiKeyRepo,
+		tokens:     tokens,
+	}
+}
+
+func (instance *authService) Register(ctx context.Context, request *domain.RegisterRequest) (*domain.UserTransformer, error) {
+	email := request.GetEmail()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		instance.log.Error("failed to hash password : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToRegister)
+	}
+
+	user := &domain.User{
+		Email:        email,
+		PasswordHash: string(hash),
+	}
+	if err := instance.userRepo.Create(ctx, user); err != nil {
+		if errors.Is(err, domain.ErrEmailTaken) {
+			return nil, responseErr.ResponseBadRequest(EmailAlreadyRegistered)
+		}
+
+		instance.log.Error("failed to create user ["+email+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToRegister)
+	}
+
+	return user.ToUserTransformer(), nil
+}
+
+// Login compares the password even when the email is unknown, so both take the same time
+func (instance *authService) Login(ctx context.Context, request *domain.LoginRequest) (*domain.TokenTransformer, error) {
+	email := request.GetEmail()
+
+	user, err := instance.userRepo.GetOneByEmail(ctx, email)
+	if err != nil {
+		instance.log.Error("failed to get user ["+email+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToLogin)
+	}
+
+	hash := dummyHash
+	if user != nil {
+		hash = []byte(user.PasswordHash)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(request.Password)); err != nil || user == nil {
+		return nil, responseErr.ResponseUnauthorized(InvalidCredentials)
+	}
+
+	return instance.issue(user)
+}
+
+// Refresh exchanges a refresh token for a new pair of tokens
+func (instance *authService) Refresh(ctx context.Context, request *domain.RefreshRequest) (*domain.TokenTransformer, error) {
+	user, err := instance.verify(ctx, request.RefreshToken, domain.TokenRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance.issue(user)
+}
+
+// Authenticate returns the user of an access token, the user must still exist
+func (instance *authService) Authenticate(ctx context.Context, token string) (*domain.User, error) {
+	return instance.verify(ctx, token, domain.TokenAccess)
+}
+
+// AuthenticateKey returns the API key of key when it wasn't revoked and didn't expire, its last
+// used time is saved at most once a minute
+func (instance *authService) AuthenticateKey(ctx context.Context, key string) (*domain.APIKey, error) {
+	apiKey, err := instance.apiKeyRepo.GetOneByHash(ctx, domain.HashAPIKey(key))
+	if err != nil {
+		instance.log.Error("failed to get API key : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetAPIKey)
+	}
+
+	now := time.Now().UTC()
+	if apiKey == nil || apiKey.IsExpired(now) {
+		return nil, responseErr.ResponseUnauthorized(InvalidAPIKey)
+	}
+
+	if apiKey.Used(now) {
+		// a failed write only loses the last used time, the request is still authenticated
+		if err := instance.apiKeyRepo.Touch(ctx, apiKey); err != nil {
+			instance.log.Error("failed to touch API key ["+strconv.FormatUint(apiKey.ID, 10)+"] : ", zap.Error(err))
+		}
+	}
+
+	return apiKey, nil
+}
+
+func (instance *authService) verify(ctx context.Context, token string, tokenType string) (*domain.User, error) {
+	id, err := instance.tokens.parse(token, tokenType)
+	if err != nil {
+		return nil, responseErr.ResponseUnauthorized(InvalidToken)
+	}
+
+	user, err := instance.userRepo.GetOneByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get user of token : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToLogin)
+	}
+
+	if user == nil {
+		return nil, responseErr.ResponseUnauthorized(InvalidToken)
+	}
+
+	return user, nil
+}
+
+func (instance *authService) issue(user *domain.User) (*domain.TokenTransformer, error) {
+	access, err := instance.tokens.sign(user, domain.TokenAccess, instance.tokens.AccessTTL)
+	if err != nil {
+		instance.log.Error("failed to sign access token : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToLogin)
+	}
+
+	refresh, err := instance.tokens.sign(user, domain.TokenRefresh, instance.tokens.RefreshTTL)
+	if err != nil {
+		instance.log.Error("failed to sign refresh token : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToLogin)
+	}
+
+	return &domain.TokenTransformer{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(instance.tokens.AccessTTL.Seconds()),
+	}, nil
+}
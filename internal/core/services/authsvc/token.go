@@ -0,0 +1,58 @@
+package authsvc
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/todo-list/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"time"
+)
+
+// dummyHash is compared against when logging in with an unknown email
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Tokens configures the HS256 signed JWTs, an access token authenticates the requests and a
+// refresh token is exchanged for a new pair once it expired
+type Tokens struct {
+	Secret     []byte
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// claims is the subject user id and the type of the token, so a refresh token can't be used
+// as an access token
+type claims struct {
+	jwt.RegisteredClaims
+	Type string `json:"typ"`
+}
+
+func (instance Tokens) sign(user *domain.User, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    instance.Issuer,
+			Subject:   strconv.FormatUint(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Type: tokenType,
+	}).SignedString(instance.Secret)
+}
+
+// parse returns the user id of a valid token of tokenType
+func (instance Tokens) parse(token string, tokenType string) (uint64, error) {
+	parsed := new(claims)
+	if _, err := jwt.ParseWithClaims(token, parsed, func(*jwt.Token) (interface{}, error) {
+		return instance.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})); err != nil {
+		return 0, err
+	}
+
+	if parsed.Type != tokenType || !parsed.VerifyIssuer(instance.Issuer, true) {
+		return 0, domain.ErrInvalidToken
+	}
+
+	return strconv.ParseUint(parsed.Subject, 10, 64)
+}
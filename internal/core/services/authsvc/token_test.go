@@ -0,0 +1,118 @@
+package authsvc
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/apikeyrps"
+	"github.com/todo-list/internal/adapter/outbound/userrps"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+var testTokens = Tokens{
+	Secret:     []byte("0123456789abcdef0123456789abcdef"),
+	Issuer:     "todo-list",
+	AccessTTL:  time.Minute,
+	RefreshTTL: time.Hour,
+}
+
+func TestTokenParse(t *testing.T) {
+	user := &domain.User{ID: 42}
+
+	access, err := testTokens.sign(user, domain.TokenAccess, time.Minute)
+	require.NoError(t, err)
+
+	id, err := testTokens.parse(access, domain.TokenAccess)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), id)
+
+	// an access token isn't a refresh token
+	_, err = testTokens.parse(access, domain.TokenRefresh)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	expired, err := testTokens.sign(user, domain.TokenAccess, -time.Minute)
+	require.NoError(t, err)
+	_, err = testTokens.parse(expired, domain.TokenAccess)
+	assert.Error(t, err)
+
+	other := testTokens
+	other.Secret = []byte("fedcba9876543210fedcba9876543210")
+	_, err = other.parse(access, domain.TokenAccess)
+	assert.Error(t, err)
+
+	other = testTokens
+	other.Issuer = "another-service"
+	_, err = other.parse(access, domain.TokenAccess)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+	_, err = testTokens.parse("not.a.token", domain.TokenAccess)
+	assert.Error(t, err)
+}
+
+func TestTokenParseRefusesOtherMethods(t *testing.T) {
+	now := time.Now()
+	registered := jwt.RegisteredClaims{
+		Issuer:    testTokens.Issuer,
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}
+
+	// signed by the same secret but with another algorithm
+	hs512, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims{RegisteredClaims: registered, Type: domain.TokenAccess}).
+		SignedString(testTokens.Secret)
+	require.NoError(t, err)
+	_, err = testTokens.parse(hs512, domain.TokenAccess)
+	assert.Error(t, err)
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims{RegisteredClaims: registered, Type: domain.TokenAccess}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = testTokens.parse(unsigned, domain.TokenAccess)
+	assert.Error(t, err)
+}
+
+func TestRefreshChecksTokenType(t *testing.T) {
+	ctx := context.Background()
+	service := NewAuthService(zap.NewNop(), userrps.NewUserMemory(), apikeyrps.NewAPIKeyMemory(), testTokens)
+
+	_, err := service.Register(ctx, &domain.RegisterRequest{Email: "Ada@Example.com", Password: "password1"})
+	require.NoError(t, err)
+
+	tokens, err := service.Login(ctx, &domain.LoginRequest{Email: "ada@example.com", Password: "password1"})
+	require.NoError(t, err)
+
+	user, err := service.Authenticate(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "ada@example.com", user.Email)
+
+	// each token is only accepted where its type is expected
+	var appErr *responseErr.AppError
+	_, err = service.Authenticate(ctx, tokens.RefreshToken)
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, fiber.StatusUnauthorized, appErr.Status)
+
+	_, err = service.Refresh(ctx, &domain.RefreshRequest{RefreshToken: tokens.AccessToken})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, fiber.StatusUnauthorized, appErr.Status)
+
+	refreshed, err := service.Refresh(ctx, &domain.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	// a token of a user that doesn't exist isn't accepted
+	unknown, err := testTokens.sign(&domain.User{ID: 99}, domain.TokenAccess, time.Minute)
+	require.NoError(t, err)
+	_, err = service.Authenticate(ctx, unknown)
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, fiber.StatusUnauthorized, appErr.Status)
+
+	_, err = service.Login(ctx, &domain.LoginRequest{Email: "ada@example.com", Password: "wrong password"})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, fiber.StatusUnauthorized, appErr.Status)
+}
@@ -0,0 +1,104 @@
+package outboxsvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// unpublishedLimit is the number of events relayed on every run
+const unpublishedLimit = 100
+
+// Retry configures the attempts of an event, the first retry waits Backoff and every next one
+// waits twice as long as the previous one
+type Retry struct {
+	Backoff     time.Duration
+	MaxAttempts int
+	// Timeout is the timeout of a publication, a claimed event is kept from other relays twice as long
+	Timeout time.Duration
+}
+
+type outboxService struct {
+	log        *zap.Logger
+	outboxRepo ports.OutboxRepository
+	publisher  ports.EventPublisher
+	events     ports.EventService
+	retry      Retry
+}
+
+// NewOutboxService relays the outbox events to events inside the application then to publisher,
+// an event is locked while it is relayed so other relays skip it
+func NewOutboxService(log *zap.Logger, outboxRepo ports.OutboxRepository, publisher ports.EventPublisher, events ports.EventService, retry Retry) ports.OutboxService {
+	return &outboxService{
+		log:        log,
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		events:     events,
+		retry:      retry,
+	}
+}
+
+// Relay publishes the unpublished events, the events of a task in the order they were written. A
+// failed event holds back the later events of its task until it is published or dead lettered, the
+// events of the other tasks go on. An event is published at least once, consumers tell retries apart
+// by the event id.
+func (instance *outboxService) Relay(ctx context.Context, now time.Time) error {
+	events, err := instance.outboxRepo.GetAllUnpublished(ctx, now, unpublishedLimit)
+	if err != nil {
+		instance.log.Error("failed to get unpublished events : ", zap.Error(err))
+		return err
+	}
+
+	// held are the tasks with an earlier event waiting for a retry or relayed by another relay
+	held := map[uint64]bool{}
+	for _, event := range events {
+		eventID := strconv.FormatUint(event.ID, 10)
+
+		if held[event.TaskID] || event.LockedUntil.After(now) {
+			held[event.TaskID] = true
+			continue
+		}
+
+		if err := instance.outboxRepo.ClaimEvent(ctx, event, now, now.Add(2*instance.retry.Timeout)); err != nil {
+			if errors.Is(err, domain.ErrEventClaimed) {
+				held[event.TaskID] = true
+				continue
+			}
+			instance.log.Error("failed to claim event ["+eventID+"] : ", zap.Error(err))
+			return err
+		}
+
+		err := instance.relay(ctx, event)
+		event.Attempted(err, instance.retry.Backoff, instance.retry.MaxAttempts)
+
+		if err := instance.outboxRepo.UpdateEvent(ctx, event); err != nil {
+			instance.log.Error("failed to save event ["+eventID+"] : ", zap.Error(err))
+			return err
+		}
+
+		if err != nil {
+			if event.DeadLetteredAt != nil {
+				instance.log.Error("failed to publish event ["+eventID+"], it is dead lettered : ", zap.Int("attempts", event.Attempts), zap.Error(err))
+				continue
+			}
+			instance.log.Warn("failed to publish event ["+eventID+"]", zap.Int("attempts", event.Attempts), zap.Error(err))
+			held[event.TaskID] = true
+		}
+	}
+
+	return nil
+}
+
+// relay hands event to the consumers inside the application first, they ignore an event they
+// already received so a failed publication can be retried safely
+func (instance *outboxService) relay(ctx context.Context, event *domain.OutboxEvent) error {
+	if err := instance.events.CreateEvent(ctx, event); err != nil {
+		return err
+	}
+
+	return instance.publisher.Publish(ctx, event)
+}
@@ -0,0 +1,123 @@
+package outboxsvc
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/outboxrps"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"github.com/todo-list/internal/core/ports/mocks"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// failingPublisher fails the events of failedTask and records the ids of the ones it published
+type failingPublisher struct {
+	failedTask uint64
+	published  []uint64
+}
+
+func (instance *failingPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	if event.TaskID == instance.failedTask {
+		return errors.New("broker is down")
+	}
+
+	instance.published = append(instance.published, event.ID)
+	return nil
+}
+
+func newTestOutbox(t *testing.T, publisher ports.EventPublisher, events ...*domain.OutboxEvent) (ports.OutboxService, ports.OutboxRepository) {
+	t.Helper()
+
+	outboxRepo := outboxrps.NewOutboxMemory()
+	require.NoError(t, outboxRepo.CreateEvents(context.Background(), events))
+
+	eventService := new(mocks.EventService)
+	eventService.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	service := NewOutboxService(zap.NewNop(), outboxRepo, publisher, eventService, Retry{
+		Backoff:     time.Minute,
+		MaxAttempts: 3,
+		Timeout:     time.Second,
+	})
+
+	return service, outboxRepo
+}
+
+func newTestEvent(taskID uint64) *domain.OutboxEvent {
+	now := time.Now().UTC()
+
+	return &domain.OutboxEvent{
+		Type:        domain.EventTaskUpdated,
+		TaskID:      taskID,
+		LockedUntil: now,
+		CreatedAt:   now,
+	}
+}
+
+func TestRelayHoldsBackOnlyFailedTask(t *testing.T) {
+	publisher := &failingPublisher{failedTask: 1}
+	service, outboxRepo := newTestOutbox(t, publisher, newTestEvent(1), newTestEvent(2), newTestEvent(1), newTestEvent(2))
+
+	require.NoError(t, service.Relay(context.Background(), time.Now()))
+
+	// the events of task 2 went on, the second event of task 1 waits for the first one
+	assert.Equal(t, []uint64{2, 4}, publisher.published)
+
+	events, err := outboxRepo.GetAllUnpublished(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, 1, events[0].Attempts)
+	assert.Equal(t, "broker is down", events[0].Error)
+	assert.Zero(t, events[1].Attempts)
+}
+
+func TestRelayRetriesWithBackoff(t *testing.T) {
+	publisher := &failingPublisher{failedTask: 1}
+	service, outboxRepo := newTestOutbox(t, publisher, newTestEvent(1))
+
+	require.NoError(t, service.Relay(context.Background(), time.Now()))
+
+	// the event isn't retried before its backoff passed
+	require.NoError(t, service.Relay(context.Background(), time.Now().Add(30*time.Second)))
+	events, err := outboxRepo.GetAllUnpublished(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, 1, events[0].Attempts)
+
+	// the second retry waits twice as long as the first one
+	require.NoError(t, service.Relay(context.Background(), time.Now().Add(2*time.Minute)))
+	events, err = outboxRepo.GetAllUnpublished(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, 2, events[0].Attempts)
+	assert.True(t, events[0].LockedUntil.After(time.Now().Add(time.Minute+30*time.Second)))
+
+	publisher.failedTask = 0
+	require.NoError(t, service.Relay(context.Background(), time.Now().Add(3*time.Minute)))
+	assert.Equal(t, []uint64{1}, publisher.published)
+
+	events, err = outboxRepo.GetAllUnpublished(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestRelayDeadLetters(t *testing.T) {
+	publisher := &failingPublisher{failedTask: 1}
+	service, outboxRepo := newTestOutbox(t, publisher, newTestEvent(1), newTestEvent(1))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, service.Relay(context.Background(), time.Now().Add(time.Duration(i)*time.Hour)))
+	}
+
+	// the first event ran out of attempts, the one following it isn't held back anymore
+	events, err := outboxRepo.GetAllUnpublished(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(2), events[0].ID)
+	assert.Equal(t, 1, events[0].Attempts)
+}
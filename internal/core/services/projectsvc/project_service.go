@@ -0,0 +1,151 @@
+package projectsvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToCreateProject = "Failed to create new project"
+	FailedToGetProject    = "Failed to get project"
+	FailedToUpdateProject = "Failed to update project"
+	FailedToDeleteProject = "Failed to delete project"
+	ProjectNotFound       = "Project not found"
+	ProjectNotEmpty       = "Project still has tasks, move or purge them first"
+)
+
+type projectService struct {
+	log         *zap.Logger
+	projectRepo ports.ProjectRepository
+	taskRepo    ports.TaskRepository
+}
+
+func NewProjectService(log *zap.Logger, projectRepo ports.ProjectRepository, taskRepo ports.TaskRepository) ports.ProjectService {
+	return &projectService{
+		log:         log,
+		projectRepo: projectRepo,
+		taskRepo:    taskRepo,
+	}
+}
+
+func (instance *projectService) Create(ctx context.Context, request *domain.CreateProjectRequest) (*domain.ProjectTransformer, error) {
+	owner, _ := domain.OwnerFromContext(ctx)
+
+	project := request.ToBase(owner)
+	if err := instance.projectRepo.Create(ctx, project); err != nil {
+		instance.log.Error("failed to create project : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToCreateProject)
+	}
+
+	return project.ToProjectTransformer(nil), nil
+}
+
+func (instance *projectService) Update(ctx context.Context, id string, request *domain.UpdateProjectRequest) error {
+	project, err := instance.getProject(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := instance.projectRepo.Update(ctx, request.ToBase(project)); err != nil {
+		instance.log.Error("failed to update project by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToUpdateProject)
+	}
+
+	return nil
+}
+
+// Delete refuses to delete a project any task belongs to, including the trashed ones
+func (instance *projectService) Delete(ctx context.Context, id string) error {
+	project, err := instance.getProject(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	counts, err := instance.taskRepo.CountByProject(ctx, []uint64{project.ID})
+	if err != nil {
+		instance.log.Error("failed to count tasks of project ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToDeleteProject)
+	}
+
+	if !counts[project.ID].IsEmpty() {
+		return responseErr.ResponseBadRequest(ProjectNotEmpty)
+	}
+
+	// a task created in the project since it was counted keeps it by its foreign key
+	if err := instance.projectRepo.Delete(ctx, project); err != nil {
+		if errors.Is(err, domain.ErrProjectNotEmpty) {
+			return responseErr.ResponseBadRequest(ProjectNotEmpty)
+		}
+		instance.log.Error("failed to delete project by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToDeleteProject)
+	}
+
+	return nil
+}
+
+func (instance *projectService) GetOneByID(ctx context.Context, id string) (*domain.ProjectTransformer, error) {
+	project, err := instance.getProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := instance.taskRepo.CountByProject(ctx, []uint64{project.ID})
+	if err != nil {
+		instance.log.Error("failed to count tasks of project ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetProject)
+	}
+
+	return project.ToProjectTransformer(counts[project.ID]), nil
+}
+
+// GetAll returns the projects with the number of their open and finished tasks
+func (instance *projectService) GetAll(ctx context.Context) ([]*domain.ProjectTransformer, error) {
+	projects, err := instance.projectRepo.GetAll(ctx)
+	if err != nil {
+		instance.log.Error("failed to get projects : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetProject)
+	}
+
+	var projectIDs []uint64
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	counts, err := instance.taskRepo.CountByProject(ctx, projectIDs)
+	if err != nil {
+		instance.log.Error("failed to count tasks of projects : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetProject)
+	}
+
+	datas := []*domain.ProjectTransformer{}
+	for _, project := range projects {
+		datas = append(datas, project.ToProjectTransformer(counts[project.ID]))
+	}
+
+	return datas, nil
+}
+
+// getProject validates the id and gets the project, the returned error is ready to be responded
+func (instance *projectService) getProject(ctx context.Context, id string) (*domain.Project, error) {
+	_, err := strconv.Atoi(id)
+	if id == "" || err != nil {
+		return nil, responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	project, err := instance.projectRepo.GetOneByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get project by id ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetProject)
+	}
+
+	if project == nil {
+		return nil, responseErr.ResponseNotFound(ProjectNotFound)
+	}
+
+	return project, nil
+}
@@ -0,0 +1,125 @@
+package remindersvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// dueReminderLimit is the number of reminders sent for every offset on every run
+const dueReminderLimit = 100
+
+// Retry configures the attempts of a reminder, the first retry waits Backoff and every next one
+// waits twice as long as the previous one
+type Retry struct {
+	Backoff     time.Duration
+	MaxAttempts int
+	// Timeout is the timeout of a notification, a claimed reminder is kept from other workers twice as long
+	Timeout time.Duration
+}
+
+type reminderService struct {
+	log      *zap.Logger
+	taskRepo ports.TaskRepository
+	notifier ports.ReminderNotifier
+	offsets  []time.Duration
+	retry    Retry
+}
+
+// NewReminderService sends the reminders of every task at each of offsets before its action time
+func NewReminderService(log *zap.Logger, taskRepo ports.TaskRepository, notifier ports.ReminderNotifier, offsets []time.Duration, retry Retry) ports.ReminderService {
+	return &reminderService{
+		log:      log,
+		taskRepo: taskRepo,
+		notifier: notifier,
+		offsets:  offsets,
+		retry:    retry,
+	}
+}
+
+// SendDue sends the reminders whose time came then retries the ones not sent yet, each one is
+// claimed before it is sent so another instance or a restart doesn't send it again. Only a worker
+// dying between sending a reminder and saving it leaves the claim to expire, the reminder is then
+// sent again so it is sent at least once.
+func (instance *reminderService) SendDue(ctx context.Context, now time.Time) error {
+	for _, offset := range instance.offsets {
+		tasks, err := instance.taskRepo.GetAllDueReminders(ctx, now, offset, dueReminderLimit)
+		if err != nil {
+			instance.log.Error("failed to get due reminders before "+offset.String()+" : ", zap.Error(err))
+			return err
+		}
+
+		for _, task := range tasks {
+			instance.send(ctx, domain.NewReminder(task, offset), now)
+		}
+	}
+
+	reminders, err := instance.taskRepo.GetAllRetryReminders(ctx, now, dueReminderLimit)
+	if err != nil {
+		instance.log.Error("failed to get reminders to retry : ", zap.Error(err))
+		return err
+	}
+
+	for _, reminder := range reminders {
+		instance.resend(ctx, reminder, now)
+	}
+
+	return nil
+}
+
+func (instance *reminderService) send(ctx context.Context, reminder *domain.Reminder, now time.Time) {
+	taskID := strconv.FormatUint(reminder.TaskID, 10)
+
+	reminder.Claimed(now.Add(2 * instance.retry.Timeout))
+	if err := instance.taskRepo.CreateReminder(ctx, reminder); err != nil {
+		if !errors.Is(err, domain.ErrReminderExists) {
+			instance.log.Error("failed to claim reminder of task ["+taskID+"] : ", zap.Error(err))
+		}
+		return
+	}
+
+	instance.deliver(ctx, reminder, now)
+}
+
+func (instance *reminderService) resend(ctx context.Context, reminder *domain.Reminder, now time.Time) {
+	taskID := strconv.FormatUint(reminder.TaskID, 10)
+
+	reminder.Claimed(now.Add(2 * instance.retry.Timeout))
+	if err := instance.taskRepo.ClaimReminder(ctx, reminder, now); err != nil {
+		if !errors.Is(err, domain.ErrReminderExists) {
+			instance.log.Error("failed to claim reminder of task ["+taskID+"] : ", zap.Error(err))
+		}
+		return
+	}
+
+	instance.deliver(ctx, reminder, now)
+}
+
+func (instance *reminderService) deliver(ctx context.Context, reminder *domain.Reminder, now time.Time) {
+	taskID := strconv.FormatUint(reminder.TaskID, 10)
+
+	var err error
+	if reminder.Outdated(now) {
+		// the task changed since the reminder was due, the reminder is given up
+		err = errors.New("task was changed or started")
+		reminder.Attempts = instance.retry.MaxAttempts
+	} else {
+		// a notification outliving its claim could be sent again by another worker
+		notifyCtx, cancel := context.WithTimeout(ctx, instance.retry.Timeout)
+		err = instance.notifier.Notify(notifyCtx, reminder)
+		cancel()
+	}
+
+	if err != nil {
+		instance.log.Warn("failed to send reminder of task ["+taskID+"]", zap.Int("attempts", reminder.Attempts), zap.Error(err))
+	}
+	reminder.Sent(err, instance.retry.Backoff, instance.retry.MaxAttempts)
+
+	if err := instance.taskRepo.UpdateReminder(ctx, reminder); err != nil {
+		instance.log.Error("failed to save reminder of task ["+taskID+"] : ", zap.Error(err))
+	}
+}
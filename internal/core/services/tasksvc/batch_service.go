@@ -0,0 +1,112 @@
+package tasksvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var FailedToRunBatch = "Failed to run batch"
+
+// errBatchFailed rolls an all or nothing batch back once one of its operations failed
+var errBatchFailed = errors.New("batch operation failed")
+
+// Batch runs the operations of request in one transaction with the same checks as the single task
+// endpoints. An all or nothing batch stops and is rolled back at the first failed operation, a per
+// item batch runs every operation in a nested transaction so only the failed ones are rolled back.
+func (instance *taskService) Batch(ctx context.Context, request *domain.BatchRequest) (*domain.BatchTransformer, error) {
+	batch := domain.NewBatchTransformer(request)
+
+	err := instance.taskRepo.Transaction(ctx, func(repo ports.TaskRepository) error {
+		for i := range request.Operations {
+			operation, result := &request.Operations[i], batch.Results[i]
+
+			if request.Mode == domain.BatchAllOrNothing {
+				if err := instance.withRepo(repo).runOperation(ctx, operation, result); err != nil {
+					return errBatchFailed
+				}
+				continue
+			}
+
+			// the failure is in result, the batch goes on
+			_ = repo.Transaction(ctx, func(item ports.TaskRepository) error {
+				return instance.withRepo(item).runOperation(ctx, operation, result)
+			})
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchFailed) {
+		instance.log.Error("failed to run batch : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToRunBatch)
+	}
+
+	batch.Committed = err == nil
+	if !batch.Committed {
+		batch.RollBack()
+	}
+	batch.Count()
+
+	return batch, nil
+}
+
+// withRepo returns the service writing through repo, the repository of a transaction
+func (instance *taskService) withRepo(repo ports.TaskRepository) *taskService {
+	service := *instance
+	service.taskRepo = repo
+
+	return &service
+}
+
+// runOperation runs operation and sets its outcome on result
+func (instance *taskService) runOperation(ctx context.Context, operation *domain.BatchOperation, result *domain.BatchResult) error {
+	taskID, err := instance.operate(ctx, operation)
+	result.TaskID = taskID
+	if err != nil {
+		result.Status = domain.BatchFailed
+		result.ErrorKey, result.ErrorMessage = responseErr.ErrKeyInternalServer, FailedToRunBatch
+
+		var appErr *responseErr.AppError
+		if errors.As(err, &appErr) {
+			result.ErrorKey, result.ErrorMessage = appErr.ErrorKey, appErr.ErrorMessage
+		}
+
+		return err
+	}
+
+	result.Status = domain.BatchSucceeded
+
+	return nil
+}
+
+func (instance *taskService) operate(ctx context.Context, operation *domain.BatchOperation) (uint64, error) {
+	id := strconv.FormatUint(operation.TaskID, 10)
+
+	switch operation.Method {
+	case domain.BatchCreate:
+		request, err := operation.CreateRequest()
+		if err != nil {
+			return 0, responseErr.ResponseBadRequest(err.Error())
+		}
+
+		task, err := instance.create(ctx, request)
+		if err != nil {
+			return 0, err
+		}
+
+		return task.ID, nil
+	case domain.BatchUpdate:
+		request, err := operation.UpdateRequest()
+		if err != nil {
+			return operation.TaskID, responseErr.ResponseBadRequest(err.Error())
+		}
+
+		return operation.TaskID, instance.Update(ctx, id, request, operation.IfMatch, operation.Scope)
+	default:
+		return operation.TaskID, instance.Delete(ctx, id, operation.IfMatch)
+	}
+}
@@ -0,0 +1,85 @@
+package tasksvc
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/outboxrps"
+	"github.com/todo-list/internal/core/domain"
+	"testing"
+	"time"
+)
+
+// newTestBatch creates a task, updates task and deletes it with a stale If-Match, so the delete fails
+func newTestBatch(t *testing.T, mode string, task *domain.Task) *domain.BatchRequest {
+	t.Helper()
+
+	created, err := json.Marshal(domain.CreateTaskRequst{Title: "created in batch", ActionTime: time.Now().Unix()})
+	require.NoError(t, err)
+	updated, err := json.Marshal(domain.UpdateTaskRequest{Title: "updated in batch"})
+	require.NoError(t, err)
+
+	return &domain.BatchRequest{
+		Mode: mode,
+		Operations: []domain.BatchOperation{
+			{Method: domain.BatchCreate, Task: created},
+			{Method: domain.BatchUpdate, TaskID: task.ID, IfMatch: task.ETag(), Task: updated},
+			{Method: domain.BatchDelete, TaskID: task.ID, IfMatch: task.ETag()},
+		},
+	}
+}
+
+func TestBatchAllOrNothing(t *testing.T) {
+	outboxRepo := outboxrps.NewOutboxMemory()
+	service, _, ctx := newTestServiceWithOutbox(t, outboxRepo)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "original",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+	})
+	events, err := outboxRepo.GetAllUnpublished(ctx, time.Now(), 100)
+	require.NoError(t, err)
+
+	batch, err := service.Batch(ctx, newTestBatch(t, domain.BatchAllOrNothing, task))
+	require.NoError(t, err)
+
+	assert.False(t, batch.Committed)
+	assert.Equal(t, 0, batch.Succeeded)
+	assert.Equal(t, 1, batch.Failed)
+	assert.Equal(t, domain.BatchRolledBack, batch.Results[0].Status)
+	assert.Zero(t, batch.Results[0].TaskID)
+	assert.Equal(t, domain.BatchRolledBack, batch.Results[1].Status)
+	assert.Equal(t, domain.BatchFailed, batch.Results[2].Status)
+
+	// neither the created task nor the update nor their events were kept
+	tasks, err := service.GetAllWithPaginate(ctx, &domain.TaskParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks.ListData, 1)
+	assert.Equal(t, "original", tasks.ListData[0].Title)
+	assert.Equal(t, task.Version, getTestTask(t, service, ctx, task.ID).Version)
+
+	after, err := outboxRepo.GetAllUnpublished(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Len(t, after, len(events))
+}
+
+func TestBatchPerItem(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "original",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+	})
+
+	batch, err := service.Batch(ctx, newTestBatch(t, domain.BatchPerItem, task))
+	require.NoError(t, err)
+
+	assert.True(t, batch.Committed)
+	assert.Equal(t, 2, batch.Succeeded)
+	assert.Equal(t, 1, batch.Failed)
+	assert.Equal(t, domain.BatchSucceeded, batch.Results[0].Status)
+	assert.Equal(t, domain.BatchSucceeded, batch.Results[1].Status)
+	assert.Equal(t, domain.BatchFailed, batch.Results[2].Status)
+
+	// only the failed delete was rolled back
+	assert.Equal(t, "created in batch", getTestTask(t, service, ctx, batch.Results[0].TaskID).Title)
+	assert.Equal(t, "updated in batch", getTestTask(t, service, ctx, task.ID).Title)
+}
@@ -0,0 +1,181 @@
+package tasksvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToAddBlocker    = "Failed to add blocker"
+	FailedToRemoveBlocker = "Failed to remove blocker"
+	BlockerNotFound       = "Blocker task not found"
+	DependencyCycle       = "Task can't be blocked by itself or by a task it blocks"
+	TaskBlocked           = "Task is blocked by unfinished tasks"
+	FinishedTaskBlocked   = "A finished task can't be blocked by an unfinished task"
+)
+
+// AddBlocker blocks the task until the blocker is finished, a task can't be blocked by a task it
+// blocks directly or through other tasks. The checks and the dependency are in one transaction holding
+// the other dependency changes, so two concurrent ones can't close a cycle.
+func (instance *taskService) AddBlocker(ctx context.Context, id string, blockerID string) error {
+	err := instance.taskRepo.Transaction(ctx, func(repo ports.TaskRepository) error {
+		return instance.withRepo(repo).addBlocker(ctx, id, blockerID)
+	})
+
+	var appErr *responseErr.AppError
+	if err != nil && !errors.As(err, &appErr) {
+		instance.log.Error("failed to block task ["+id+"] by task ["+blockerID+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToAddBlocker)
+	}
+
+	return err
+}
+
+func (instance *taskService) addBlocker(ctx context.Context, id string, blockerID string) error {
+	if err := instance.taskRepo.LockDependencies(ctx); err != nil {
+		return err
+	}
+
+	task, err := instance.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = strconv.Atoi(blockerID)
+	if blockerID == "" || err != nil {
+		return responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	blocker, err := instance.taskRepo.GetOneByID(ctx, blockerID)
+	if err != nil {
+		instance.log.Error("failed to get task by id ["+blockerID+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToGetTask)
+	}
+
+	if blocker == nil {
+		return responseErr.ResponseNotFound(BlockerNotFound)
+	}
+
+	if task.ID == blocker.ID {
+		return responseErr.ResponseBadRequest(DependencyCycle)
+	}
+
+	// a finished task would be blocked without being reopened
+	if task.IsFinished && task.IsBlockedBy([]*domain.Task{blocker}) {
+		return responseErr.ResponseBadRequest(FinishedTaskBlocked)
+	}
+
+	cyclic, err := instance.isBlocking(ctx, task.ID, blocker)
+	if err != nil {
+		instance.log.Error("failed to get blockers of task ["+blockerID+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToAddBlocker)
+	}
+
+	if cyclic {
+		return responseErr.ResponseBadRequest(DependencyCycle)
+	}
+
+	if err := instance.taskRepo.CreateDependency(ctx, &domain.TaskDependency{
+		TaskID:    task.ID,
+		BlockerID: blocker.ID,
+	}); err != nil {
+		if errors.Is(err, domain.ErrDependencyExists) {
+			return responseErr.ResponseBadRequest(err.Error())
+		}
+		if errors.Is(err, domain.ErrDependencyMissing) {
+			return responseErr.ResponseNotFound(err.Error())
+		}
+		instance.log.Error("failed to block task ["+id+"] by task ["+blockerID+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToAddBlocker)
+	}
+
+	return nil
+}
+
+// RemoveBlocker unblocks the task, the blocker may already be purged
+func (instance *taskService) RemoveBlocker(ctx context.Context, id string, blockerID string) error {
+	task, err := instance.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	blocker, err := strconv.ParseUint(blockerID, 10, 64)
+	if err != nil {
+		return responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	if err := instance.taskRepo.DeleteDependency(ctx, &domain.TaskDependency{
+		TaskID:    task.ID,
+		BlockerID: blocker,
+	}); err != nil {
+		if errors.Is(err, domain.ErrDependencyNotFound) {
+			return responseErr.ResponseNotFound(err.Error())
+		}
+		instance.log.Error("failed to unblock task ["+id+"] from task ["+blockerID+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToRemoveBlocker)
+	}
+
+	return nil
+}
+
+// isBlocking reports whether the task with taskID blocks task, directly or through the tasks blocking it
+func (instance *taskService) isBlocking(ctx context.Context, taskID uint64, task *domain.Task) (bool, error) {
+	visited := map[uint64]bool{task.ID: true}
+
+	ids := []uint64{task.ID}
+	for len(ids) > 0 {
+		blockers, err := instance.taskRepo.GetAllBlockers(ctx, ids)
+		if err != nil {
+			return false, err
+		}
+
+		ids = nil
+		for _, blocker := range blockers {
+			if blocker.ID == taskID {
+				return true, nil
+			}
+
+			if !visited[blocker.ID] {
+				visited[blocker.ID] = true
+				ids = append(ids, blocker.ID)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// isBlocked reports whether one of the blockers of task is still open
+func (instance *taskService) isBlocked(ctx context.Context, task *domain.Task) (bool, error) {
+	blockers, err := instance.taskRepo.GetAllBlockers(ctx, []uint64{task.ID})
+	if err != nil {
+		return false, err
+	}
+
+	return task.IsBlockedBy(blockers), nil
+}
+
+// checkBlockers refuses to finish task while one of its blockers is open, the returned error is ready
+// to be responded
+func (instance *taskService) checkBlockers(ctx context.Context, task *domain.Task, wasFinished bool) error {
+	if !task.IsFinished || wasFinished {
+		return nil
+	}
+
+	blocked, err := instance.isBlocked(ctx, task)
+	if err != nil {
+		instance.log.Error("failed to get blockers of task ["+strconv.FormatUint(task.ID, 10)+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToGetTask)
+	}
+
+	if blocked {
+		return responseErr.ResponseBadRequest(TaskBlocked)
+	}
+
+	return nil
+}
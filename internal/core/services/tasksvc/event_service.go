@@ -0,0 +1,15 @@
+package tasksvc
+
+import (
+	"github.com/todo-list/internal/core/domain"
+)
+
+// recordUpdated records the updated event of task and the finished one when it wasn't finished before,
+// the events are written to the outbox by the repository with the task
+func recordUpdated(task *domain.Task, wasFinished bool) {
+	task.Record(domain.EventTaskUpdated)
+
+	if !wasFinished && task.IsFinished {
+		task.Record(domain.EventTaskFinished)
+	}
+}
@@ -0,0 +1,183 @@
+package tasksvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"io"
+	"sort"
+)
+
+const exportPageSize = 100
+
+var (
+	FailedToExportTasks = "Failed to export tasks"
+	FailedToImportTasks = "Failed to import tasks"
+	ParentRowFailed     = "Parent row wasn't imported"
+	ParentRowCycle      = "Parent_ID: rows can't be the parents of each other."
+)
+
+// errImportFailed rolls an import back once one of its rows failed
+var errImportFailed = errors.New("import row failed")
+
+// Export writes every task matching the filters of params to w a page at a time, in the order of
+// the cursor pagination. The answer is already sent when a page fails to be read, the file is cut
+// short.
+func (instance *taskService) Export(ctx context.Context, params *domain.TaskParams, format string, w io.Writer) error {
+	writer := domain.NewTaskRecordWriter(format, w)
+
+	query := *params
+	query.Limit = exportPageSize
+	query.Cursor, query.Sort, query.Order = nil, nil, nil
+
+	var cursor *domain.TaskCursor
+	for {
+		tasks, err := instance.taskRepo.GetAllWithCursor(ctx, &query, cursor)
+		if err != nil {
+			instance.log.Error("failed to get task with cursor : ", zap.Error(err))
+			return responseErr.ResponseInternalServerError(FailedToExportTasks)
+		}
+
+		for _, task := range tasks {
+			if err := writer.Write(task.ToTaskRecord()); err != nil {
+				return err
+			}
+		}
+
+		if len(tasks) < exportPageSize {
+			break
+		}
+
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		cursor = tasks[len(tasks)-1].ToTaskCursor()
+	}
+
+	return writer.Close()
+}
+
+// Import creates the tasks of a file in one transaction, nothing is imported when any row is
+// invalid or fails to be created and every failed row is reported
+func (instance *taskService) Import(ctx context.Context, format string, file io.Reader) (*domain.ImportTransformer, error) {
+	records, rowErrors, err := domain.ReadTaskRecords(format, file)
+	if err != nil {
+		return nil, responseErr.ResponseBadRequest(err.Error())
+	}
+
+	result := &domain.ImportTransformer{
+		Errors: rowErrors,
+	}
+
+	if len(rowErrors) == 0 {
+		err = instance.taskRepo.Transaction(ctx, func(repo ports.TaskRepository) error {
+			result.Errors = instance.withRepo(repo).importRecords(ctx, records)
+			if len(result.Errors) > 0 {
+				return errImportFailed
+			}
+
+			return nil
+		})
+		if err != nil && !errors.Is(err, errImportFailed) {
+			instance.log.Error("failed to import tasks : ", zap.Error(err))
+			return nil, responseErr.ResponseInternalServerError(FailedToImportTasks)
+		}
+
+		result.Committed = err == nil
+		if result.Committed {
+			result.Imported = len(records)
+		}
+	}
+
+	if result.Errors == nil {
+		result.Errors = []*domain.RowError{}
+	}
+	sort.Slice(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+
+	return result, nil
+}
+
+// importRecords creates the tasks of records, a task whose parent is another row is created after
+// it so its Parent_ID can be replaced by the id of the created parent
+func (instance *taskService) importRecords(ctx context.Context, records []*domain.TaskRecord) []*domain.RowError {
+	var rowErrors []*domain.RowError
+
+	inFile := map[uint64]bool{}
+	for _, record := range records {
+		if record.ID != 0 {
+			inFile[record.ID] = true
+		}
+	}
+
+	created := map[uint64]uint64{}
+	failed := map[uint64]bool{}
+	fail := func(row int, record *domain.TaskRecord, message string) {
+		rowErrors = append(rowErrors, &domain.RowError{Row: row + 1, ErrorMessage: message})
+		if record.ID != 0 {
+			failed[record.ID] = true
+		}
+	}
+
+	pending := make([]int, len(records))
+	for i := range records {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		var waiting []int
+
+		for _, row := range pending {
+			record := *records[row]
+
+			if record.ParentID != nil && inFile[*record.ParentID] {
+				if failed[*record.ParentID] {
+					fail(row, &record, ParentRowFailed)
+					continue
+				}
+
+				parentID, ok := created[*record.ParentID]
+				if !ok {
+					waiting = append(waiting, row)
+					continue
+				}
+				record.ParentID = &parentID
+			}
+
+			// every row is created in a nested transaction, a failed row doesn't abort the following ones
+			task := record.ToBase()
+			err := instance.taskRepo.Transaction(ctx, func(repo ports.TaskRepository) error {
+				return instance.withRepo(repo).createTask(ctx, task)
+			})
+			if err != nil {
+				message := FailedToImportTasks
+				var appErr *responseErr.AppError
+				if errors.As(err, &appErr) {
+					message = appErr.ErrorMessage
+				}
+
+				fail(row, &record, message)
+				continue
+			}
+
+			if record.ID != 0 {
+				created[record.ID] = task.ID
+			}
+		}
+
+		// the rows still waiting are parents of each other
+		if len(waiting) == len(pending) {
+			for _, row := range waiting {
+				fail(row, records[row], ParentRowCycle)
+			}
+			break
+		}
+		pending = waiting
+	}
+
+	return rowErrors
+}
@@ -0,0 +1,61 @@
+package tasksvc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/core/domain"
+	"strings"
+	"testing"
+)
+
+func TestImportReportsEveryFailedRow(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	file := `[
+		{"Task_ID": 10, "Title": "parent", "Action_Time": 1792320674},
+		{"Task_ID": 11, "Title": "lost", "Action_Time": 1792320674, "Project_ID": 42},
+		{"Task_ID": 12, "Title": "child", "Action_Time": 1792320674, "Parent_ID": 10},
+		{"Task_ID": 13, "Title": "also lost", "Action_Time": 1792320674, "Project_ID": 43}
+	]`
+
+	result, err := service.Import(ctx, domain.FormatJSON, strings.NewReader(file))
+	require.NoError(t, err)
+
+	// every row reports its own error, nothing is imported
+	assert.False(t, result.Committed)
+	assert.Zero(t, result.Imported)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, 2, result.Errors[0].Row)
+	assert.Equal(t, ProjectNotFound, result.Errors[0].ErrorMessage)
+	assert.Equal(t, 4, result.Errors[1].Row)
+	assert.Equal(t, ProjectNotFound, result.Errors[1].ErrorMessage)
+
+	tasks, err := service.GetAllWithPaginate(ctx, &domain.TaskParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, tasks.ListData)
+}
+
+func TestImportLinksParentRows(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	file := `[
+		{"Task_ID": 12, "Title": "child", "Action_Time": 1792320674, "Parent_ID": 10},
+		{"Task_ID": 10, "Title": "parent", "Action_Time": 1792320674}
+	]`
+
+	result, err := service.Import(ctx, domain.FormatJSON, strings.NewReader(file))
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	assert.True(t, result.Committed)
+	assert.Equal(t, 2, result.Imported)
+
+	tasks, err := service.GetAllWithPaginate(ctx, &domain.TaskParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks.ListData, 2)
+
+	// the child row points to the task created from the parent row
+	titles := map[string]*domain.TaskTransformer{}
+	for _, task := range tasks.ListData {
+		titles[task.Title] = task
+	}
+	require.NotNil(t, titles["child"].ParentID)
+	assert.Equal(t, titles["parent"].ID, *titles["child"].ParentID)
+}
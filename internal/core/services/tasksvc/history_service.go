@@ -0,0 +1,61 @@
+package tasksvc
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToGetHistory = "Failed to get task history"
+	HistoryNotFound    = "Task history not found"
+)
+
+// GetHistory lists the changes of a task newest first, the history of a purged task is kept
+func (instance *taskService) GetHistory(ctx context.Context, id string, params *domain.AuditLogParams) (*domain.AuditLogPagination, error) {
+	var (
+		datas   []*domain.AuditLogTransformer
+		maxPage int
+	)
+
+	_, err := strconv.Atoi(id)
+	if id == "" || err != nil {
+		return nil, responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	audits, total, err := instance.taskRepo.GetAllAuditLogWithPaginate(ctx, id, params)
+	if err != nil {
+		instance.log.Error("failed to get history of task ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetHistory)
+	}
+
+	if total == 0 {
+		return nil, responseErr.ResponseNotFound(HistoryNotFound)
+	}
+
+	for _, audit := range audits {
+		datas = append(datas, audit.ToAuditLogTransformer())
+	}
+
+	if total%int64(params.Limit) > 0 {
+		maxPage = int(total/int64(params.Limit)) + 1
+	} else {
+		maxPage = int(total / int64(params.Limit))
+	}
+
+	return &domain.AuditLogPagination{
+		ListData: datas,
+		PaginationData: &domain.Pagination{
+			CurrentPage:    params.Page,
+			MaxDataPerPage: params.Limit,
+			MaxPage:        maxPage,
+			TotalAllData:   total,
+		},
+	}, nil
+}
@@ -0,0 +1,199 @@
+package tasksvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToCreateObjective = "Failed to create new objective"
+	FailedToUpdateObjective = "Failed to update objective"
+	FailedToDeleteObjective = "Failed to delete objective"
+	ObjectiveNotFound       = "Objective not found"
+)
+
+func (instance *taskService) AddObjective(ctx context.Context, id string, request *domain.CreateObjectiveRequest, ifMatch string) error {
+	task, err := instance.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	wasFinished := task.IsFinished
+	objective := request.ToBase(task)
+	task.Objective = append(task.Objective, objective)
+	task.IsFinished = task.IsAllObjectivesFinished()
+	if err := instance.holdFinished(ctx, task); err != nil {
+		return err
+	}
+	if err := instance.checkBlockers(ctx, task, wasFinished); err != nil {
+		return err
+	}
+	recordUpdated(task, wasFinished)
+
+	if err := instance.taskRepo.CreateObjective(ctx, task, objective); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to create objective of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToCreateObjective)
+	}
+
+	instance.materializeFinished(ctx, task)
+	if task.IsFinished != wasFinished {
+		instance.rollUp(ctx, task.ParentID)
+	}
+
+	return nil
+}
+
+func (instance *taskService) RenameObjective(ctx context.Context, id string, objectiveID string, request *domain.RenameObjectiveRequest, ifMatch string) error {
+	task, objective, err := instance.getObjective(ctx, id, objectiveID)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	objective.ObjectiveName = request.ObjectiveName
+	task.Record(domain.EventTaskUpdated)
+
+	if err := instance.taskRepo.UpdateObjective(ctx, task, objective); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to rename objective ["+objectiveID+"] of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToUpdateObjective)
+	}
+
+	return nil
+}
+
+func (instance *taskService) ToggleObjective(ctx context.Context, id string, objectiveID string, ifMatch string) error {
+	task, objective, err := instance.getObjective(ctx, id, objectiveID)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	wasFinished := task.IsFinished
+	objective.IsFinished = !objective.IsFinished
+	task.IsFinished = task.IsAllObjectivesFinished()
+	if err := instance.holdFinished(ctx, task); err != nil {
+		return err
+	}
+	if err := instance.checkBlockers(ctx, task, wasFinished); err != nil {
+		return err
+	}
+	recordUpdated(task, wasFinished)
+
+	if err := instance.taskRepo.UpdateObjective(ctx, task, objective); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to toggle objective ["+objectiveID+"] of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToUpdateObjective)
+	}
+
+	instance.materializeFinished(ctx, task)
+	if task.IsFinished != wasFinished {
+		instance.rollUp(ctx, task.ParentID)
+	}
+
+	return nil
+}
+
+func (instance *taskService) DeleteObjective(ctx context.Context, id string, objectiveID string, ifMatch string) error {
+	task, objective, err := instance.getObjective(ctx, id, objectiveID)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	wasFinished := task.IsFinished
+	var objectives []*domain.Objective
+	for _, obj := range task.Objective {
+		if obj.ID != objective.ID {
+			objectives = append(objectives, obj)
+		}
+	}
+	task.Objective = objectives
+	task.IsFinished = task.IsAllObjectivesFinished()
+	if err := instance.holdFinished(ctx, task); err != nil {
+		return err
+	}
+	if err := instance.checkBlockers(ctx, task, wasFinished); err != nil {
+		return err
+	}
+	recordUpdated(task, wasFinished)
+
+	if err := instance.taskRepo.DeleteObjective(ctx, task, objective); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to delete objective ["+objectiveID+"] of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToDeleteObjective)
+	}
+
+	instance.materializeFinished(ctx, task)
+	if task.IsFinished != wasFinished {
+		instance.rollUp(ctx, task.ParentID)
+	}
+
+	return nil
+}
+
+// getTask validates the id and gets the task, the returned error is ready to be responded
+func (instance *taskService) getTask(ctx context.Context, id string) (*domain.Task, error) {
+	_, err := strconv.Atoi(id)
+	if id == "" || err != nil {
+		return nil, responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	task, err := instance.taskRepo.GetOneByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get task by id ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetTask)
+	}
+
+	if task == nil {
+		return nil, responseErr.ResponseNotFound(TaskNotFound)
+	}
+
+	return task, nil
+}
+
+// getObjective gets the task and one of its objectives, the returned error is ready to be responded
+func (instance *taskService) getObjective(ctx context.Context, id string, objectiveID string) (*domain.Task, *domain.Objective, error) {
+	objID, err := strconv.ParseUint(objectiveID, 10, 64)
+	if err != nil {
+		return nil, nil, responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	task, err := instance.getTask(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objective := task.GetObjective(objID)
+	if objective == nil {
+		return nil, nil, responseErr.ResponseNotFound(ObjectiveNotFound)
+	}
+
+	return task, objective, nil
+}
@@ -0,0 +1,168 @@
+package tasksvc
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// dueRecurringLimit is the number of due recurring tasks materialized on every run
+const dueRecurringLimit = 100
+
+// MaterializeDue creates the next occurrence of the recurring tasks whose action time passed
+func (instance *taskService) MaterializeDue(ctx context.Context, now time.Time) error {
+	tasks, err := instance.taskRepo.GetAllDueRecurring(ctx, now, dueRecurringLimit)
+	if err != nil {
+		instance.log.Error("failed to get due recurring tasks : ", zap.Error(err))
+		return err
+	}
+
+	for _, task := range tasks {
+		// a conflict means the task was just edited or finished, it is picked up again on the next run
+		if err := instance.materialize(ctx, task, now); err != nil {
+			instance.log.Error("failed to create next occurrence of task ["+strconv.FormatUint(task.ID, 10)+"] : ", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// saveTask saves updated, the new state of task. Editing only this occurrence detaches it from its
+// series and creates the next occurrence from task as it was, editing all the future ones carries
+// the changes over to the next occurrence. A finished occurrence is followed by the next one.
+func (instance *taskService) saveTask(ctx context.Context, task *domain.Task, updated *domain.Task, scope string) error {
+	var (
+		next *domain.Task
+		err  error
+	)
+
+	if scope != domain.ScopeThis && task.HasNext && task.NextID != nil {
+		next, err = instance.taskRepo.GetOneByID(ctx, strconv.FormatUint(*task.NextID, 10))
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case next == nil:
+			// the next occurrence was deleted, a restarted series creates a new one
+		case next.IsFinished || next.HasNext:
+			// the series already went on from the next occurrence, it isn't restarted from task
+			updated.HasNext = updated.IsRecurring()
+		default:
+			return instance.saveSeries(ctx, task, updated, next)
+		}
+		next = nil
+	}
+
+	switch {
+	case scope == domain.ScopeThis && task.IsRecurring() && !task.HasNext:
+		next, err = task.NextOccurrence(time.Now())
+		updated.StopRecurrence()
+	case updated.IsFinished && updated.IsRecurring() && !updated.HasNext:
+		next, err = updated.NextOccurrence(time.Now())
+		updated.HasNext = true
+	}
+	if err != nil {
+		return err
+	}
+
+	if next == nil {
+		return instance.taskRepo.Update(ctx, updated)
+	}
+
+	next.Record(domain.EventTaskCreated)
+
+	if err := instance.taskRepo.SaveOccurrence(ctx, updated, next); err != nil {
+		return err
+	}
+
+	instance.rollUp(ctx, next.ParentID)
+
+	return nil
+}
+
+// saveSeries saves updated, the new state of task edited for all the future occurrences, with next,
+// the occurrence already following it. The changes are carried over to next, it is moved to the trash
+// when the series ends before it or only detached from the series when it has subtasks.
+func (instance *taskService) saveSeries(ctx context.Context, task *domain.Task, updated *domain.Task, next *domain.Task) error {
+	carried, err := updated.CarryOver(task, next, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if carried == next {
+		return instance.taskRepo.Update(ctx, updated)
+	}
+
+	var subtasks []*domain.Task
+	if carried == nil {
+		if subtasks, err = instance.getSubtasks(ctx, next); err != nil {
+			return err
+		}
+	}
+
+	// the next occurrence replaces the one a restarted series would create
+	updated.HasNext = updated.IsRecurring()
+
+	return instance.taskRepo.Transaction(ctx, func(repo ports.TaskRepository) error {
+		if err := repo.Update(ctx, updated); err != nil {
+			return err
+		}
+
+		switch {
+		case carried != nil:
+			recordUpdated(carried, next.IsFinished)
+			return repo.Update(ctx, carried)
+		case len(subtasks) > 0:
+			next.StopRecurrence()
+			recordUpdated(next, next.IsFinished)
+			return repo.Update(ctx, next)
+		default:
+			next.Record(domain.EventTaskDeleted)
+			return repo.Delete(ctx, next)
+		}
+	})
+}
+
+// materialize creates the occurrence following task, a task whose series ended is only marked so it
+// isn't picked up again
+func (instance *taskService) materialize(ctx context.Context, task *domain.Task, now time.Time) error {
+	if !task.IsRecurring() || task.HasNext {
+		return nil
+	}
+
+	next, err := task.NextOccurrence(now)
+	if err != nil {
+		return err
+	}
+
+	task.HasNext = true
+	if next == nil {
+		return instance.taskRepo.Update(ctx, task)
+	}
+
+	next.Record(domain.EventTaskCreated)
+
+	if err := instance.taskRepo.SaveOccurrence(ctx, task, next); err != nil {
+		return err
+	}
+
+	instance.rollUp(ctx, next.ParentID)
+
+	return nil
+}
+
+// materializeFinished creates the occurrence following task once all its objectives are finished,
+// the objective was already saved so a failure is only logged
+func (instance *taskService) materializeFinished(ctx context.Context, task *domain.Task) {
+	if !task.IsFinished {
+		return
+	}
+
+	if err := instance.materialize(ctx, task, time.Now()); err != nil {
+		instance.log.Error("failed to create next occurrence of task ["+strconv.FormatUint(task.ID, 10)+"] : ", zap.Error(err))
+	}
+}
@@ -0,0 +1,97 @@
+package tasksvc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/core/domain"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMaterializeDue(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	rule := "FREQ=DAILY;COUNT=3"
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "stand up",
+		ActionTime: start.Unix(),
+		Objectives: []string{"notes"},
+		Recurrence: &rule,
+	})
+
+	require.NoError(t, service.MaterializeDue(ctx, time.Now()))
+
+	task = getTestTask(t, service, ctx, task.ID)
+	require.True(t, task.HasNext)
+	require.NotNil(t, task.NextID)
+
+	next := getTestTask(t, service, ctx, *task.NextID)
+	assert.Equal(t, "stand up", next.Title)
+	assert.True(t, next.ActionTime.Equal(start.AddDate(0, 0, 1).UTC()))
+	assert.True(t, next.RecurrenceStart.Equal(*task.RecurrenceStart))
+	require.Len(t, next.Objective, 1)
+	assert.False(t, next.Objective[0].IsFinished)
+
+	// the occurrence was already followed, another run doesn't create it twice
+	require.NoError(t, service.MaterializeDue(ctx, time.Now()))
+	assert.Equal(t, *task.NextID, *getTestTask(t, service, ctx, task.ID).NextID)
+}
+
+func TestMaterializeDueEndsSeries(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	rule := "FREQ=DAILY;COUNT=2"
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "backup",
+		ActionTime: time.Now().Add(-time.Hour).Unix(),
+		Recurrence: &rule,
+	})
+
+	// the second occurrence is the last one of the series
+	require.NoError(t, service.MaterializeDue(ctx, time.Now()))
+	second := getTestTask(t, service, ctx, *getTestTask(t, service, ctx, task.ID).NextID)
+
+	require.NoError(t, service.MaterializeDue(ctx, second.ActionTime.Add(time.Minute)))
+
+	second = getTestTask(t, service, ctx, second.ID)
+	assert.True(t, second.HasNext)
+	assert.Nil(t, second.NextID)
+}
+
+func TestUpdateKeepsUnchangedRecurrence(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	rule := "FREQ=WEEKLY;COUNT=4"
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "review",
+		ActionTime: start.Unix(),
+		Recurrence: &rule,
+	})
+	require.NoError(t, service.MaterializeDue(ctx, time.Now()))
+
+	next := getTestTask(t, service, ctx, *getTestTask(t, service, ctx, task.ID).NextID)
+	id := strconv.FormatUint(next.ID, 10)
+
+	// putting the occurrence again with its rule doesn't restart the series from it
+	unchanged := rule
+	require.NoError(t, service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "weekly review", Recurrence: &unchanged}, "", domain.ScopeAll))
+
+	next = getTestTask(t, service, ctx, next.ID)
+	assert.Equal(t, "weekly review", next.Title)
+	require.NotNil(t, next.RecurrenceStart)
+	assert.True(t, next.RecurrenceStart.Equal(start.UTC()))
+
+	// a new rule restarts the series from the occurrence, only for all the future ones
+	changed := "FREQ=DAILY;COUNT=4"
+	err := service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "daily review", Recurrence: &changed}, "", domain.ScopeThis)
+	assert.Error(t, err)
+
+	require.NoError(t, service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "daily review", Recurrence: &changed}, "", domain.ScopeAll))
+
+	next = getTestTask(t, service, ctx, next.ID)
+	assert.Equal(t, changed, *next.Recurrence)
+	assert.True(t, next.RecurrenceStart.Equal(next.ActionTime))
+
+	// only this occurrence may be put again as long as the rule is unchanged
+	require.NoError(t, service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "review", Recurrence: &changed}, "", domain.ScopeThis))
+}
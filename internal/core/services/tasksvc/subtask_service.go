@@ -0,0 +1,266 @@
+package tasksvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToGetSubtasks    = "Failed to get subtasks"
+	FailedToMoveTask       = "Failed to move task"
+	ParentNotFound         = "Parent task not found"
+	ParentTrashed          = "Parent task is in trash, restore it first"
+	TaskCycle              = "Task can't be moved under itself or one of its subtasks"
+	TaskTooDeep            = "Subtasks can't be nested more than 5 levels deep"
+	TaskHasSubtasks        = "Task still has subtasks, delete them first"
+	TaskHasTrashedSubtasks = "Task still has subtasks in trash, purge them first"
+)
+
+// GetSubtree gets the task with its subtasks nested, the trashed subtasks are left out
+func (instance *taskService) GetSubtree(ctx context.Context, id string) (*domain.TaskTransformer, error) {
+	task, err := instance.getTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := instance.getDescendants(ctx, task)
+	if err != nil {
+		instance.log.Error("failed to get subtasks of task ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetSubtasks)
+	}
+
+	var descendants []*domain.Task
+	for _, level := range levels {
+		for _, descendant := range level {
+			if descendant.DeletedAt == nil {
+				descendants = append(descendants, descendant)
+			}
+		}
+	}
+
+	return task.ToTaskTree(descendants), nil
+}
+
+// Move moves the task and its subtasks under another task or to the top level, the finished state of
+// the old & new parent is rolled up
+func (instance *taskService) Move(ctx context.Context, id string, request *domain.MoveTaskRequest, ifMatch string) error {
+	task, err := instance.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	levels, err := instance.getDescendants(ctx, task)
+	if err != nil {
+		instance.log.Error("failed to get subtasks of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToMoveTask)
+	}
+
+	if err := instance.checkParent(ctx, request.ParentID, task.ID, len(levels)+1); err != nil {
+		return err
+	}
+
+	oldParentID := task.ParentID
+	task.SetParent(request.ParentID)
+	task.Record(domain.EventTaskUpdated)
+	if err := instance.taskRepo.Update(ctx, task); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to move task by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToMoveTask)
+	}
+
+	instance.rollUp(ctx, oldParentID, task.ParentID)
+
+	return nil
+}
+
+// checkParent verifies a task can be placed under the parent task without making a cycle or a tree
+// deeper than domain.MaxTaskDepth, height is the number of levels of the subtree of the task. A nil or
+// 0 id places it at the top level. The returned error is ready to be responded.
+func (instance *taskService) checkParent(ctx context.Context, parentID *uint64, taskID uint64, height int) error {
+	if parentID == nil || *parentID == 0 {
+		return nil
+	}
+
+	if *parentID == taskID {
+		return responseErr.ResponseBadRequest(TaskCycle)
+	}
+
+	id := strconv.FormatUint(*parentID, 10)
+	parent, err := instance.taskRepo.GetOneByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get task by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToGetTask)
+	}
+
+	if parent == nil {
+		return responseErr.ResponseNotFound(ParentNotFound)
+	}
+
+	ancestors, err := instance.getAncestors(ctx, parent)
+	if err != nil {
+		instance.log.Error("failed to get parents of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToGetTask)
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.ID == taskID {
+			return responseErr.ResponseBadRequest(TaskCycle)
+		}
+	}
+
+	if len(ancestors)+1+height > domain.MaxTaskDepth {
+		return responseErr.ResponseBadRequest(TaskTooDeep)
+	}
+
+	return nil
+}
+
+// getAncestors gets the parent of task, the parent of its parent and so on up to the top level task
+func (instance *taskService) getAncestors(ctx context.Context, task *domain.Task) ([]*domain.Task, error) {
+	var ancestors []*domain.Task
+
+	// a chain longer than the depth limit can't be stored, stopping there guards against a cycle
+	for parentID := task.ParentID; parentID != nil && len(ancestors) < domain.MaxTaskDepth; {
+		parent, err := instance.taskRepo.GetOneByID(ctx, strconv.FormatUint(*parentID, 10))
+		if err != nil {
+			return nil, err
+		}
+
+		if parent == nil {
+			break
+		}
+
+		ancestors = append(ancestors, parent)
+		parentID = parent.ParentID
+	}
+
+	return ancestors, nil
+}
+
+// getDescendants gets the subtasks of task level by level, trashed ones included
+func (instance *taskService) getDescendants(ctx context.Context, task *domain.Task) ([][]*domain.Task, error) {
+	var levels [][]*domain.Task
+
+	ids := []uint64{task.ID}
+	for len(levels) < domain.MaxTaskDepth {
+		subtasks, err := instance.taskRepo.GetAllByParent(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(subtasks) == 0 {
+			break
+		}
+
+		levels = append(levels, subtasks)
+		ids = nil
+		for _, subtask := range subtasks {
+			ids = append(ids, subtask.ID)
+		}
+	}
+
+	return levels, nil
+}
+
+// getSubtasks gets the subtasks of task which aren't in the trash
+func (instance *taskService) getSubtasks(ctx context.Context, task *domain.Task) ([]*domain.Task, error) {
+	subtasks, err := instance.taskRepo.GetAllByParent(ctx, []uint64{task.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*domain.Task
+	for _, subtask := range subtasks {
+		if subtask.DeletedAt == nil {
+			active = append(active, subtask)
+		}
+	}
+
+	return active, nil
+}
+
+// holdFinished keeps task open while one of its subtasks is, the returned error is ready to be responded
+func (instance *taskService) holdFinished(ctx context.Context, task *domain.Task) error {
+	if !task.IsFinished {
+		return nil
+	}
+
+	subtasks, err := instance.getSubtasks(ctx, task)
+	if err != nil {
+		instance.log.Error("failed to get subtasks of task ["+strconv.FormatUint(task.ID, 10)+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToGetSubtasks)
+	}
+
+	task.IsFinished = domain.AllFinished(subtasks)
+
+	return nil
+}
+
+// rollUp derives the finished state of the parents from their subtasks after one of them changed,
+// the subtask was already saved so a failure is only logged
+func (instance *taskService) rollUp(ctx context.Context, parentIDs ...*uint64) {
+	for _, parentID := range parentIDs {
+		if parentID == nil {
+			continue
+		}
+
+		if err := instance.rollUpParent(ctx, parentID); err != nil {
+			instance.log.Error("failed to roll up subtasks of task ["+strconv.FormatUint(*parentID, 10)+"] : ", zap.Error(err))
+		}
+	}
+}
+
+// rollUpParent saves the finished state of the parent and of its own parents as long as it changes,
+// a task without subtasks keeps the state of its objectives
+func (instance *taskService) rollUpParent(ctx context.Context, parentID *uint64) error {
+	for depth := 0; parentID != nil && depth < domain.MaxTaskDepth; depth++ {
+		parent, err := instance.taskRepo.GetOneByID(ctx, strconv.FormatUint(*parentID, 10))
+		if err != nil {
+			return err
+		}
+
+		if parent == nil {
+			return nil
+		}
+
+		subtasks, err := instance.getSubtasks(ctx, parent)
+		if err != nil {
+			return err
+		}
+
+		finished := parent.RollUp(subtasks)
+		if finished && !parent.IsFinished {
+			// a blocked parent stays open until its blockers are finished
+			blocked, err := instance.isBlocked(ctx, parent)
+			if err != nil {
+				return err
+			}
+			finished = !blocked
+		}
+
+		if len(subtasks) == 0 || finished == parent.IsFinished {
+			return nil
+		}
+
+		wasFinished := parent.IsFinished
+		parent.IsFinished = finished
+		recordUpdated(parent, wasFinished)
+		if err := instance.saveTask(ctx, parent, parent, domain.ScopeAll); err != nil {
+			return err
+		}
+
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package tasksvc
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+)
+
+var FailedToGetTags = "Failed to get tags"
+
+// GetTags lists the tags of the user with the number of tasks labelled with each, the most used first
+func (instance *taskService) GetTags(ctx context.Context) ([]*domain.TagCount, error) {
+	tags, err := instance.taskRepo.GetAllTags(ctx)
+	if err != nil {
+		instance.log.Error("failed to get tags : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetTags)
+	}
+
+	if tags == nil {
+		tags = []*domain.TagCount{}
+	}
+
+	return tags, nil
+}
@@ -1,10 +1,14 @@
 package tasksvc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"github.com/todo-list/internal/core/domain"
 	"github.com/todo-list/internal/core/ports"
 	responseErr "github.com/todo-list/internal/error"
+	"github.com/todo-list/pkg/mergepatch"
 	"go.uber.org/zap"
 	"strconv"
 )
@@ -15,30 +19,65 @@ var (
 	FailedToUpdateTask    = "Failed to update task"
 	TaskNotFound          = "Task not found"
 	FailedToDeleteTask    = "Failed to delete task"
+	TaskModified          = "Task was modified since it was read"
+	FailedToGetProject    = "Failed to get project"
+	ProjectNotFound       = "Project not found"
 )
 
 type taskService struct {
-	log      *zap.Logger
-	taskRepo ports.TaskRepository
+	log         *zap.Logger
+	taskRepo    ports.TaskRepository
+	projectRepo ports.ProjectRepository
 }
 
-func NewTaskService(log *zap.Logger, taskRepo ports.TaskRepository) ports.TaskService {
+func NewTaskService(log *zap.Logger, taskRepo ports.TaskRepository, projectRepo ports.ProjectRepository) ports.TaskService {
 	return &taskService{
-		log:      log,
-		taskRepo: taskRepo,
+		log:         log,
+		taskRepo:    taskRepo,
+		projectRepo: projectRepo,
 	}
 }
 
 func (instance *taskService) Create(ctx context.Context, request *domain.CreateTaskRequst) error {
-	if err := instance.taskRepo.Create(ctx, request.ToBase()); err != nil {
+	_, err := instance.create(ctx, request)
+	return err
+}
+
+// create returns the created task so a batch can report its id
+func (instance *taskService) create(ctx context.Context, request *domain.CreateTaskRequst) (*domain.Task, error) {
+	task := request.ToBase()
+	if err := instance.createTask(ctx, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// createTask saves a new task once its project and parent are checked
+func (instance *taskService) createTask(ctx context.Context, task *domain.Task) error {
+	if err := instance.checkProject(ctx, task.ProjectID); err != nil {
+		return err
+	}
+
+	if err := instance.checkParent(ctx, task.ParentID, 0, 1); err != nil {
+		return err
+	}
+
+	task.OwnerID, _ = domain.OwnerFromContext(ctx)
+	task.Record(domain.EventTaskCreated)
+	if err := instance.taskRepo.Create(ctx, task); err != nil {
 		instance.log.Error("failed to create task : ", zap.Error(err))
 		return responseErr.ResponseInternalServerError(FailedToCreateNewTask)
 	}
 
+	instance.rollUp(ctx, task.ParentID)
+
 	return nil
 }
 
-func (instance *taskService) Update(ctx context.Context, id string, request *domain.UpdateTaskRequest) error {
+// Update replaces the task, ifMatch is the If-Match header the client sent and may be empty.
+// scope tells whether only this occurrence of a recurring task is edited or all the future ones.
+func (instance *taskService) Update(ctx context.Context, id string, request *domain.UpdateTaskRequest, ifMatch string, scope string) error {
 	// get one by id for checking
 	_, err := strconv.Atoi(id)
 	if id == "" || err != nil {
@@ -55,11 +94,119 @@ func (instance *taskService) Update(ctx context.Context, id string, request *dom
 		return responseErr.ResponseNotFound(TaskNotFound)
 	}
 
-	if err := instance.taskRepo.Update(ctx, request.ToBase(task)); err != nil {
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	// objective ids must belong to the task, the other objectives are created
+	for _, obj := range request.Objectives {
+		if obj.ID != 0 && task.GetObjective(obj.ID) == nil {
+			return responseErr.ResponseNotFound(ObjectiveNotFound)
+		}
+	}
+
+	if scope == domain.ScopeThis && request.ChangesRecurrence(task) {
+		return responseErr.ResponseBadRequest(domain.ErrScopeRecurrence.Error())
+	}
+
+	if err := instance.checkProject(ctx, request.ProjectID); err != nil {
+		return err
+	}
+
+	updated := request.ToBase(task)
+	if err := instance.holdFinished(ctx, updated); err != nil {
+		return err
+	}
+
+	if err := instance.checkBlockers(ctx, updated, task.IsFinished); err != nil {
+		return err
+	}
+
+	recordUpdated(updated, task.IsFinished)
+	if err := instance.saveTask(ctx, task, updated, scope); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
 		instance.log.Error("failed to update task by id ["+id+"]", zap.Error(err))
 		return responseErr.ResponseInternalServerError(FailedToUpdateTask)
 	}
 
+	if updated.IsFinished != task.IsFinished {
+		instance.rollUp(ctx, updated.ParentID)
+	}
+
+	return nil
+}
+
+// Patch applies a JSON merge patch to the title, action time and objective list of the task
+func (instance *taskService) Patch(ctx context.Context, id string, patch []byte, ifMatch string, scope string) error {
+	task, err := instance.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	document, err := json.Marshal(task.ToPatchTaskRequest())
+	if err != nil {
+		instance.log.Error("failed to encode task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToUpdateTask)
+	}
+
+	patched, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return responseErr.ResponseBadRequest(err.Error())
+	}
+
+	request := new(domain.PatchTaskRequest)
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		return responseErr.ResponseBadRequest(err.Error())
+	}
+
+	if err := request.Validate(); err != nil {
+		return responseErr.ResponseBadRequest(err.Error())
+	}
+
+	for _, obj := range request.Objectives {
+		if obj.ID != 0 && task.GetObjective(obj.ID) == nil {
+			return responseErr.ResponseNotFound(ObjectiveNotFound)
+		}
+	}
+
+	if err := instance.checkProject(ctx, request.ProjectID); err != nil {
+		return err
+	}
+
+	updated := request.ToBase(task)
+	if scope == domain.ScopeThis && request.ChangesRecurrence(task) {
+		return responseErr.ResponseBadRequest(domain.ErrScopeRecurrence.Error())
+	}
+
+	if err := instance.holdFinished(ctx, updated); err != nil {
+		return err
+	}
+
+	if err := instance.checkBlockers(ctx, updated, task.IsFinished); err != nil {
+		return err
+	}
+
+	recordUpdated(updated, task.IsFinished)
+	if err := instance.saveTask(ctx, task, updated, scope); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to patch task by id ["+id+"]", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToUpdateTask)
+	}
+
+	if updated.IsFinished != task.IsFinished {
+		instance.rollUp(ctx, updated.ParentID)
+	}
+
 	return nil
 }
 
@@ -82,7 +229,7 @@ func (instance *taskService) GetOneByID(ctx context.Context, id string) (*domain
 	return task.ToTaskTransformer(), nil
 }
 
-func (instance *taskService) Delete(ctx context.Context, id string) error {
+func (instance *taskService) Delete(ctx context.Context, id string, ifMatch string) error {
 	_, err := strconv.Atoi(id)
 	if id == "" || err != nil {
 		return responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
@@ -98,11 +245,31 @@ func (instance *taskService) Delete(ctx context.Context, id string) error {
 		return responseErr.ResponseNotFound(TaskNotFound)
 	}
 
-	if err := instance.taskRepo.Delete(ctx, id); err != nil {
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	subtasks, err := instance.getSubtasks(ctx, task)
+	if err != nil {
+		instance.log.Error("failed to get subtasks of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToDeleteTask)
+	}
+
+	if len(subtasks) > 0 {
+		return responseErr.ResponseBadRequest(TaskHasSubtasks)
+	}
+
+	task.Record(domain.EventTaskDeleted)
+	if err := instance.taskRepo.Delete(ctx, task); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
 		instance.log.Error("failed to delete task by id ["+id+"] : ", zap.Error(err))
 		return responseErr.ResponseInternalServerError(FailedToDeleteTask)
 	}
 
+	instance.rollUp(ctx, task.ParentID)
+
 	return nil
 }
 
@@ -116,6 +283,10 @@ func (instance *taskService) GetAllWithPaginate(ctx context.Context, params *dom
 		params.Limit = 100
 	}
 
+	if params.Cursor != nil {
+		return instance.getAllWithCursor(ctx, params)
+	}
+
 	tasks, total, err := instance.taskRepo.GetAllWithPaginate(ctx, params)
 	if err != nil {
 		instance.log.Error("failed to get task with limit : ", zap.Error(err))
@@ -134,7 +305,7 @@ func (instance *taskService) GetAllWithPaginate(ctx context.Context, params *dom
 
 	return &domain.TaskPagination{
 		ListData: datas,
-		PaginationData: domain.Pagination{
+		PaginationData: &domain.Pagination{
 			CurrentPage:    params.Page,
 			MaxDataPerPage: params.Limit,
 			MaxPage:        maxPage,
@@ -142,3 +313,62 @@ func (instance *taskService) GetAllWithPaginate(ctx context.Context, params *dom
 		},
 	}, nil
 }
+
+// getAllWithCursor is paginating by keyset without counting all tasks, one extra task is
+// fetched to know whether there is a next page
+func (instance *taskService) getAllWithCursor(ctx context.Context, params *domain.TaskParams) (*domain.TaskPagination, error) {
+	var (
+		datas      []*domain.TaskTransformer
+		nextCursor *string
+	)
+
+	cursor, err := domain.DecodeTaskCursor(*params.Cursor)
+	if err != nil {
+		return nil, responseErr.ResponseBadRequest(err.Error())
+	}
+
+	query := *params
+	query.Limit = params.Limit + 1
+
+	tasks, err := instance.taskRepo.GetAllWithCursor(ctx, &query, cursor)
+	if err != nil {
+		instance.log.Error("failed to get task with cursor : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetTask)
+	}
+
+	if len(tasks) > params.Limit {
+		tasks = tasks[:params.Limit]
+		next := tasks[len(tasks)-1].ToTaskCursor().Encode()
+		nextCursor = &next
+	}
+
+	for _, task := range tasks {
+		datas = append(datas, task.ToTaskTransformer())
+	}
+
+	return &domain.TaskPagination{
+		ListData:   datas,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// checkProject verifies the project a task is moved to belongs to the user, a nil or 0 id
+// removes the task from its project
+func (instance *taskService) checkProject(ctx context.Context, projectID *uint64) error {
+	if projectID == nil || *projectID == 0 {
+		return nil
+	}
+
+	id := strconv.FormatUint(*projectID, 10)
+	project, err := instance.projectRepo.GetOneByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get project by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToGetProject)
+	}
+
+	if project == nil {
+		return responseErr.ResponseNotFound(ProjectNotFound)
+	}
+
+	return nil
+}
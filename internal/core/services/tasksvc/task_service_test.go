@@ -0,0 +1,188 @@
+package tasksvc
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/outboxrps"
+	"github.com/todo-list/internal/adapter/outbound/projectrps"
+	"github.com/todo-list/internal/adapter/outbound/taskrps"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testOwner = 1
+
+// newTestService returns the service backed by the memory repositories, and the context of testOwner
+func newTestService(t *testing.T) (*taskService, ports.TaskRepository, context.Context) {
+	t.Helper()
+
+	return newTestServiceWithOutbox(t, outboxrps.NewOutboxMemory())
+}
+
+// newTestServiceWithOutbox is newTestService writing the task events to outboxRepo
+func newTestServiceWithOutbox(t *testing.T, outboxRepo ports.OutboxRepository) (*taskService, ports.TaskRepository, context.Context) {
+	t.Helper()
+
+	taskRepo := taskrps.NewTaskMemory(outboxRepo)
+	service := NewTaskService(zap.NewNop(), taskRepo, projectrps.NewProjectMemory()).(*taskService)
+
+	return service, taskRepo, domain.WithOwner(context.Background(), testOwner)
+}
+
+// createTestTask creates a task through the service and returns it as stored
+func createTestTask(t *testing.T, service *taskService, ctx context.Context, request *domain.CreateTaskRequst) *domain.Task {
+	t.Helper()
+
+	task, err := service.create(ctx, request)
+	require.NoError(t, err)
+
+	return getTestTask(t, service, ctx, task.ID)
+}
+
+func getTestTask(t *testing.T, service *taskService, ctx context.Context, id uint64) *domain.Task {
+	t.Helper()
+
+	task, err := service.taskRepo.GetOneByID(ctx, strconv.FormatUint(id, 10))
+	require.NoError(t, err)
+	require.NotNil(t, task)
+
+	return task
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var appErr *responseErr.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, status, appErr.Status)
+}
+
+func TestUpdateIfMatch(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "write report",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+		Objectives: []string{"draft"},
+	})
+	id := strconv.FormatUint(task.ID, 10)
+	readETag := task.ETag()
+
+	// the first writer holding the read version wins and bumps the version
+	err := service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "first"}, readETag, domain.ScopeAll)
+	require.NoError(t, err)
+
+	updated := getTestTask(t, service, ctx, task.ID)
+	assert.Equal(t, "first", updated.Title)
+	assert.Equal(t, task.Version+1, updated.Version)
+
+	// the second writer read the same version, its update is refused
+	err = service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "second"}, readETag, domain.ScopeAll)
+	assertStatus(t, err, fiber.StatusPreconditionFailed)
+	assert.Equal(t, "first", getTestTask(t, service, ctx, task.ID).Title)
+
+	// without If-Match the update isn't conditional
+	err = service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "third"}, "", domain.ScopeAll)
+	require.NoError(t, err)
+	assert.Equal(t, "third", getTestTask(t, service, ctx, task.ID).Title)
+}
+
+func TestPatchAndDeleteIfMatch(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "water plants",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+	})
+	id := strconv.FormatUint(task.ID, 10)
+	staleETag := task.ETag()
+
+	require.NoError(t, service.Patch(ctx, id, []byte(`{"Title":"water the plants"}`), staleETag, domain.ScopeAll))
+
+	err := service.Patch(ctx, id, []byte(`{"Title":"water flowers"}`), staleETag, domain.ScopeAll)
+	assertStatus(t, err, fiber.StatusPreconditionFailed)
+
+	err = service.Delete(ctx, id, staleETag)
+	assertStatus(t, err, fiber.StatusPreconditionFailed)
+
+	require.NoError(t, service.Delete(ctx, id, getTestTask(t, service, ctx, task.ID).ETag()))
+}
+
+func TestObjectiveIfMatch(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "move house",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+		Objectives: []string{"pack", "clean"},
+	})
+	id := strconv.FormatUint(task.ID, 10)
+	objectiveID := strconv.FormatUint(task.Objective[0].ID, 10)
+	staleETag := task.ETag()
+
+	require.NoError(t, service.ToggleObjective(ctx, id, objectiveID, staleETag))
+
+	err := service.ToggleObjective(ctx, id, objectiveID, staleETag)
+	assertStatus(t, err, fiber.StatusPreconditionFailed)
+
+	err = service.RenameObjective(ctx, id, objectiveID, &domain.RenameObjectiveRequest{ObjectiveName: "pack boxes"}, staleETag)
+	assertStatus(t, err, fiber.StatusPreconditionFailed)
+
+	err = service.DeleteObjective(ctx, id, objectiveID, staleETag)
+	assertStatus(t, err, fiber.StatusPreconditionFailed)
+
+	err = service.AddObjective(ctx, id, &domain.CreateObjectiveRequest{ObjectiveName: "label"}, staleETag)
+	assertStatus(t, err, fiber.StatusPreconditionFailed)
+
+	stored := getTestTask(t, service, ctx, task.ID)
+	assert.Len(t, stored.Objective, 2)
+	assert.True(t, stored.GetObjective(task.Objective[0].ID).IsFinished)
+	assert.Equal(t, "pack", stored.GetObjective(task.Objective[0].ID).ObjectiveName)
+}
+
+func TestUpdateVersionConflict(t *testing.T) {
+	service, taskRepo, ctx := newTestService(t)
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "renew passport",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+	})
+
+	// another request saves the task between the read and the write of this one
+	stale := getTestTask(t, service, ctx, task.ID)
+	concurrent := getTestTask(t, service, ctx, task.ID)
+	concurrent.Title = "renew id card"
+	require.NoError(t, taskRepo.Update(ctx, concurrent))
+
+	stale.Title = "renew visa"
+	assert.ErrorIs(t, taskRepo.Update(ctx, stale), domain.ErrTaskVersionConflict)
+	assert.Equal(t, "renew id card", getTestTask(t, service, ctx, task.ID).Title)
+}
+
+func TestUpdateWithoutObjectivesKeepsFinished(t *testing.T) {
+	service, _, ctx := newTestService(t)
+	blocker := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "get quotes",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+		Objectives: []string{"call"},
+	})
+	task := createTestTask(t, service, ctx, &domain.CreateTaskRequst{
+		Title:      "fix roof",
+		ActionTime: time.Now().Add(time.Hour).Unix(),
+		Objectives: []string{"buy tiles"},
+	})
+	id := strconv.FormatUint(task.ID, 10)
+	require.NoError(t, service.AddBlocker(ctx, id, strconv.FormatUint(blocker.ID, 10)))
+
+	// a put without an objective list keeps the open objective, the blocked task isn't finished by it
+	require.NoError(t, service.Update(ctx, id, &domain.UpdateTaskRequest{Title: "fix the roof"}, "", domain.ScopeAll))
+
+	updated := getTestTask(t, service, ctx, task.ID)
+	assert.Equal(t, "fix the roof", updated.Title)
+	assert.False(t, updated.IsFinished)
+	require.Len(t, updated.Objective, 1)
+	assert.False(t, updated.Objective[0].IsFinished)
+}
@@ -0,0 +1,114 @@
+package tasksvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToRestoreTask = "Failed to restore task"
+	FailedToPurgeTask   = "Failed to purge task"
+	TrashedTaskNotFound = "Task not found in trash"
+)
+
+// GetTrash lists the deleted tasks with the same filters, sorts & pagination as the active ones
+func (instance *taskService) GetTrash(ctx context.Context, params *domain.TaskParams) (*domain.TaskPagination, error) {
+	query := *params
+	query.Trashed = true
+
+	return instance.GetAllWithPaginate(ctx, &query)
+}
+
+func (instance *taskService) Restore(ctx context.Context, id string, ifMatch string) error {
+	task, err := instance.getTrashedTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	// a subtask can't be restored out of the trash while its parent is still in it
+	if task.ParentID != nil {
+		parentID := strconv.FormatUint(*task.ParentID, 10)
+		parent, err := instance.taskRepo.GetOneByID(ctx, parentID)
+		if err != nil {
+			instance.log.Error("failed to get task by id ["+parentID+"] : ", zap.Error(err))
+			return responseErr.ResponseInternalServerError(FailedToRestoreTask)
+		}
+
+		if parent == nil {
+			return responseErr.ResponseBadRequest(ParentTrashed)
+		}
+	}
+
+	task.Record(domain.EventTaskUpdated)
+	if err := instance.taskRepo.Restore(ctx, task); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to restore task by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToRestoreTask)
+	}
+
+	instance.rollUp(ctx, task.ParentID)
+
+	return nil
+}
+
+// Purge permanently deletes a task, only tasks in the trash can be purged
+func (instance *taskService) Purge(ctx context.Context, id string, ifMatch string) error {
+	task, err := instance.getTrashedTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !task.MatchETag(ifMatch) {
+		return responseErr.ResponsePreconditionFailed(TaskModified)
+	}
+
+	subtasks, err := instance.taskRepo.GetAllByParent(ctx, []uint64{task.ID})
+	if err != nil {
+		instance.log.Error("failed to get subtasks of task ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToPurgeTask)
+	}
+
+	if len(subtasks) > 0 {
+		return responseErr.ResponseBadRequest(TaskHasTrashedSubtasks)
+	}
+
+	if err := instance.taskRepo.Purge(ctx, task); err != nil {
+		if errors.Is(err, domain.ErrTaskVersionConflict) {
+			return responseErr.ResponsePreconditionFailed(TaskModified)
+		}
+		instance.log.Error("failed to purge task by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToPurgeTask)
+	}
+
+	return nil
+}
+
+// getTrashedTask validates the id and gets the task from the trash, the returned error is ready to be responded
+func (instance *taskService) getTrashedTask(ctx context.Context, id string) (*domain.Task, error) {
+	_, err := strconv.Atoi(id)
+	if id == "" || err != nil {
+		return nil, responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	task, err := instance.taskRepo.GetTrashedByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get trashed task by id ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetTask)
+	}
+
+	if task == nil {
+		return nil, responseErr.ResponseNotFound(TrashedTaskNotFound)
+	}
+
+	return task, nil
+}
@@ -0,0 +1,70 @@
+package webhooksvc
+
+import (
+	"context"
+	"errors"
+	"github.com/todo-list/internal/core/domain"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// dueDeliveryLimit is the number of deliveries attempted on every run
+const dueDeliveryLimit = 100
+
+// Retry configures the attempts of a delivery, the first retry waits Backoff and every next one
+// waits twice as long as the previous one
+type Retry struct {
+	Backoff     time.Duration
+	MaxAttempts int
+	// Timeout is the timeout of a request, a claimed delivery is kept from other workers twice as long
+	Timeout time.Duration
+}
+
+// DeliverDue attempts the pending deliveries whose next attempt time came
+func (instance *webhookService) DeliverDue(ctx context.Context, now time.Time) error {
+	deliveries, err := instance.webhookRepo.GetAllDueDeliveries(ctx, now, dueDeliveryLimit)
+	if err != nil {
+		instance.log.Error("failed to get due webhook deliveries : ", zap.Error(err))
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		instance.deliver(ctx, delivery, now)
+	}
+
+	return nil
+}
+
+func (instance *webhookService) deliver(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time) {
+	deliveryID := strconv.FormatUint(delivery.ID, 10)
+
+	if err := instance.webhookRepo.ClaimDelivery(ctx, delivery, now, now.Add(2*instance.retry.Timeout)); err != nil {
+		if !errors.Is(err, domain.ErrDeliveryClaimed) {
+			instance.log.Error("failed to claim webhook delivery ["+deliveryID+"] : ", zap.Error(err))
+		}
+		return
+	}
+
+	var (
+		status int
+		err    error
+	)
+	if delivery.Webhook == nil || !delivery.Webhook.IsActive {
+		// the webhook was disabled after the event, the delivery is given up
+		err = errors.New("webhook is not active")
+		delivery.Attempts = instance.retry.MaxAttempts
+	} else {
+		status, err = instance.sender.Send(ctx, delivery.Webhook, delivery)
+	}
+
+	delivery.Attempted(status, err, instance.retry.Backoff, instance.retry.MaxAttempts)
+	if delivery.Status != domain.DeliverySuccess {
+		instance.log.Warn("failed to deliver webhook delivery ["+deliveryID+"]",
+			zap.Int("attempts", delivery.Attempts), zap.Int("status", status), zap.Error(err))
+	}
+
+	if err := instance.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		instance.log.Error("failed to save webhook delivery ["+deliveryID+"] : ", zap.Error(err))
+	}
+}
@@ -0,0 +1,69 @@
+package webhooksvc
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-list/internal/adapter/outbound/webhookrps"
+	"github.com/todo-list/internal/core/domain"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// statusSender answers every delivery with status and records how many it was sent
+type statusSender struct {
+	status int
+	sent   int
+}
+
+func (instance *statusSender) Send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	instance.sent++
+	return instance.status, nil
+}
+
+func TestDeliverDueRetriesWithBackoff(t *testing.T) {
+	ctx := domain.WithSystem(context.Background())
+	webhookRepo := webhookrps.NewWebhookMemory()
+	sender := &statusSender{status: http.StatusServiceUnavailable}
+	service := NewWebhookService(zap.NewNop(), webhookRepo, sender, Retry{
+		Backoff:     time.Minute,
+		MaxAttempts: 3,
+		Timeout:     time.Second,
+	})
+
+	webhook := &domain.Webhook{OwnerID: 1, URL: "http://localhost/hook", Secret: "secret", Events: domain.EventTaskCreated, IsActive: true}
+	require.NoError(t, webhookRepo.Create(ctx, webhook))
+	require.NoError(t, webhookRepo.CreateDeliveries(ctx, []*domain.WebhookDelivery{
+		domain.NewWebhookDelivery(webhook, &domain.OutboxEvent{ID: 1, Type: domain.EventTaskCreated, Payload: "{}"}),
+	}))
+
+	require.NoError(t, service.DeliverDue(ctx, time.Now()))
+	assert.Equal(t, 1, sender.sent)
+
+	// the failed delivery isn't attempted again before its backoff passed
+	require.NoError(t, service.DeliverDue(ctx, time.Now().Add(30*time.Second)))
+	assert.Equal(t, 1, sender.sent)
+
+	require.NoError(t, service.DeliverDue(ctx, time.Now().Add(time.Minute+time.Second)))
+	assert.Equal(t, 2, sender.sent)
+
+	// the second retry waits twice as long
+	require.NoError(t, service.DeliverDue(ctx, time.Now().Add(90*time.Second)))
+	assert.Equal(t, 2, sender.sent)
+
+	sender.status = http.StatusOK
+	require.NoError(t, service.DeliverDue(ctx, time.Now().Add(3*time.Minute)))
+	assert.Equal(t, 3, sender.sent)
+
+	deliveries, total, err := webhookRepo.GetAllDeliveriesWithPaginate(ctx, webhook, &domain.WebhookDeliveryParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, domain.DeliverySuccess, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+
+	// a delivered event isn't sent again
+	require.NoError(t, service.DeliverDue(ctx, time.Now().Add(time.Hour)))
+	assert.Equal(t, 3, sender.sent)
+}
@@ -0,0 +1,40 @@
+package webhooksvc
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+type eventService struct {
+	log         *zap.Logger
+	webhookRepo ports.WebhookRepository
+}
+
+// NewEventService queues a delivery of every event relayed from the outbox for each webhook
+// subscribed to it, the deliveries are sent by DeliverDue
+func NewEventService(log *zap.Logger, webhookRepo ports.WebhookRepository) ports.EventService {
+	return &eventService{
+		log:         log,
+		webhookRepo: webhookRepo,
+	}
+}
+
+// CreateEvent can be called again with the same event when relaying it failed, the deliveries
+// already queued for it are kept. Only the webhooks of the owner of the event are read.
+func (instance *eventService) CreateEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	webhooks, err := instance.webhookRepo.GetAll(domain.WithOwner(ctx, event.OwnerID))
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event) {
+			deliveries = append(deliveries, domain.NewWebhookDelivery(webhook, event))
+		}
+	}
+
+	return instance.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
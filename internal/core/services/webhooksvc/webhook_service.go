@@ -0,0 +1,170 @@
+package webhooksvc
+
+import (
+	"context"
+	"github.com/todo-list/internal/core/domain"
+	"github.com/todo-list/internal/core/ports"
+	responseErr "github.com/todo-list/internal/error"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+var (
+	FailedToCreateWebhook = "Failed to create new webhook"
+	FailedToGetWebhook    = "Failed to get webhook"
+	FailedToUpdateWebhook = "Failed to update webhook"
+	FailedToDeleteWebhook = "Failed to delete webhook"
+	FailedToGetDeliveries = "Failed to get webhook deliveries"
+	WebhookNotFound       = "Webhook not found"
+)
+
+type webhookService struct {
+	log         *zap.Logger
+	webhookRepo ports.WebhookRepository
+	sender      ports.WebhookSender
+	retry       Retry
+}
+
+func NewWebhookService(log *zap.Logger, webhookRepo ports.WebhookRepository, sender ports.WebhookSender, retry Retry) ports.WebhookService {
+	return &webhookService{
+		log:         log,
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		retry:       retry,
+	}
+}
+
+// Create returns the webhook with its secret, it is the only time the secret is returned
+func (instance *webhookService) Create(ctx context.Context, request *domain.CreateWebhookRequest) (*domain.WebhookTransformer, error) {
+	webhook, err := request.ToBase()
+	if err != nil {
+		instance.log.Error("failed to generate webhook secret : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToCreateWebhook)
+	}
+	webhook.OwnerID, _ = domain.OwnerFromContext(ctx)
+
+	if err := instance.webhookRepo.Create(ctx, webhook); err != nil {
+		instance.log.Error("failed to create webhook : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToCreateWebhook)
+	}
+
+	transformer := webhook.ToWebhookTransformer()
+	transformer.Secret = webhook.Secret
+
+	return transformer, nil
+}
+
+func (instance *webhookService) Update(ctx context.Context, id string, request *domain.UpdateWebhookRequest) error {
+	webhook, err := instance.getWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := instance.webhookRepo.Update(ctx, request.ToBase(webhook)); err != nil {
+		instance.log.Error("failed to update webhook by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToUpdateWebhook)
+	}
+
+	return nil
+}
+
+func (instance *webhookService) Delete(ctx context.Context, id string) error {
+	webhook, err := instance.getWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := instance.webhookRepo.Delete(ctx, webhook); err != nil {
+		instance.log.Error("failed to delete webhook by id ["+id+"] : ", zap.Error(err))
+		return responseErr.ResponseInternalServerError(FailedToDeleteWebhook)
+	}
+
+	return nil
+}
+
+func (instance *webhookService) GetOneByID(ctx context.Context, id string) (*domain.WebhookTransformer, error) {
+	webhook, err := instance.getWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook.ToWebhookTransformer(), nil
+}
+
+func (instance *webhookService) GetAll(ctx context.Context) ([]*domain.WebhookTransformer, error) {
+	webhooks, err := instance.webhookRepo.GetAll(ctx)
+	if err != nil {
+		instance.log.Error("failed to get webhooks : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetWebhook)
+	}
+
+	datas := []*domain.WebhookTransformer{}
+	for _, webhook := range webhooks {
+		datas = append(datas, webhook.ToWebhookTransformer())
+	}
+
+	return datas, nil
+}
+
+// GetDeliveries is the delivery log of a webhook, newest first
+func (instance *webhookService) GetDeliveries(ctx context.Context, id string, params *domain.WebhookDeliveryParams) (*domain.WebhookDeliveryPagination, error) {
+	var (
+		datas   []*domain.WebhookDeliveryTransformer
+		maxPage int
+	)
+
+	webhook, err := instance.getWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	deliveries, total, err := instance.webhookRepo.GetAllDeliveriesWithPaginate(ctx, webhook, params)
+	if err != nil {
+		instance.log.Error("failed to get deliveries of webhook ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetDeliveries)
+	}
+
+	for _, delivery := range deliveries {
+		datas = append(datas, delivery.ToWebhookDeliveryTransformer())
+	}
+
+	if total%int64(params.Limit) > 0 {
+		maxPage = int(total/int64(params.Limit)) + 1
+	} else {
+		maxPage = int(total / int64(params.Limit))
+	}
+
+	return &domain.WebhookDeliveryPagination{
+		ListData: datas,
+		PaginationData: &domain.Pagination{
+			CurrentPage:    params.Page,
+			MaxDataPerPage: params.Limit,
+			MaxPage:        maxPage,
+			TotalAllData:   total,
+		},
+	}, nil
+}
+
+// getWebhook validates the id and gets the webhook, the returned error is ready to be responded
+func (instance *webhookService) getWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	_, err := strconv.Atoi(id)
+	if id == "" || err != nil {
+		return nil, responseErr.ResponseBadRequest(responseErr.ErrBadRequest.Error())
+	}
+
+	webhook, err := instance.webhookRepo.GetOneByID(ctx, id)
+	if err != nil {
+		instance.log.Error("failed to get webhook by id ["+id+"] : ", zap.Error(err))
+		return nil, responseErr.ResponseInternalServerError(FailedToGetWebhook)
+	}
+
+	if webhook == nil {
+		return nil, responseErr.ResponseNotFound(WebhookNotFound)
+	}
+
+	return webhook, nil
+}
@@ -12,6 +12,9 @@ var (
 	ErrKeyParams         = "error_params"
 	ErrKeyInternalServer = "error_internal_server"
 	ErrKeyIDNotFound     = "error_id_not_found"
+	ErrKeyConflict       = "error_conflict"
+	ErrKeyUnauthorized   = "error_unauthorized"
+	ErrKeyForbidden      = "error_forbidden"
 )
 
 type AppErrorOption func(*AppError)
@@ -88,3 +91,27 @@ func ResponseNotFound(errMessage string) error {
 			errMessage,
 			ErrKeyIDNotFound))
 }
+
+// ResponsePreconditionFailed is used when a resource was changed by someone else since the client read it
+func ResponsePreconditionFailed(errMessage string) error {
+	return New(fiber.StatusPreconditionFailed,
+		WithDefinition(
+			errMessage,
+			ErrKeyConflict))
+}
+
+// ResponseUnauthorized is used when a request has no valid credentials
+func ResponseUnauthorized(errMessage string) error {
+	return New(fiber.StatusUnauthorized,
+		WithDefinition(
+			errMessage,
+			ErrKeyUnauthorized))
+}
+
+// ResponseForbidden is used when the credentials of a request don't allow it
+func ResponseForbidden(errMessage string) error {
+	return New(fiber.StatusForbidden,
+		WithDefinition(
+			errMessage,
+			ErrKeyForbidden))
+}
@@ -1,7 +1,11 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
 	"fmt"
+	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -10,15 +14,32 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	viperPkg "github.com/spf13/viper"
 	baseApp "github.com/todo-list/internal/app"
+	"github.com/todo-list/internal/core/services/authsvc"
+	"github.com/todo-list/internal/core/services/remindersvc"
+	"github.com/todo-list/internal/core/services/webhooksvc"
 	"github.com/todo-list/pkg/logger"
 	"github.com/todo-list/pkg/postgres"
+	redisPkg "github.com/todo-list/pkg/redis"
+	"github.com/todo-list/pkg/sqlite"
 	"github.com/todo-list/pkg/viper"
+	"gorm.io/gorm"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
+	"time"
+)
+
+const (
+	// minAuthSecret is the length of a HS256 key, placeholderAuthSecret is the secret the config
+	// used to ship with
+	minAuthSecret         = 32
+	placeholderAuthSecret = "change-me-in-production"
+
+	exportPath = "/task/export"
 )
 
 func Run() {
@@ -35,17 +56,65 @@ func Run() {
 		log.Fatal(err)
 	}
 
-	//load connection postgre
-	pg, err := postgres.Connect()
-	if err != nil {
-		log.Fatal(err)
+	storage := viperPkg.GetString("storage.driver")
+
+	//load connection database
+	var (
+		pg    *gorm.DB
+		lite  *gorm.DB
+		sqlDB *sql.DB
+		err   error
+	)
+	switch storage {
+	case baseApp.StorageMemory:
+	case baseApp.StorageSQLite:
+		lite, err = sqlite.Connect()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := sqlite.Migrate(lite, filepath.Join(basepath, viperPkg.GetString("sqlite.migration"))); err != nil {
+			log.Fatal(err)
+		}
+		sqlDB, err = lite.DB()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer sqlDB.Close()
+	default:
+		pg, err = postgres.Connect()
+		if err != nil {
+			log.Fatal(err)
+		}
+		sqlDB, err = pg.DB()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer sqlDB.Close()
 	}
-	sqlDB, err := pg.DB()
-	if err != nil {
-		log.Fatal(err)
+
+	//load connection redis for caching
+	var rdb *redis.Client
+	if viperPkg.GetBool("redis.cache.enabled") {
+		rdb, err = redisPkg.Connect()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer rdb.Close()
 	}
 
-	defer sqlDB.Close()
+	//load connection redis for publishing the outbox events, the cache connection is reused
+	outboxRdb := rdb
+	if viperPkg.GetString("outbox.publisher") == baseApp.PublisherRedis && outboxRdb == nil {
+		outboxRdb, err = redisPkg.Connect()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer outboxRdb.Close()
+	}
 
 	zap, err := logger.Initialize()
 	if err != nil {
@@ -58,18 +127,88 @@ func Run() {
 	app.Use(
 		recover.New(),
 		compress.New(),
-		etag.New(),
+		// the export is streamed, hashing it for an ETag would read the whole stream into memory
+		etag.New(etag.Config{
+			Next: func(c *fiber.Ctx) bool {
+				return strings.TrimSuffix(c.Path(), "/") == exportPath
+			},
+		}),
 		cors.New(),
 		fiberlog.New(),
 	)
 
+	var reminderOffsets []time.Duration
+	for _, offset := range viperPkg.GetStringSlice("reminder.offsets") {
+		duration, err := time.ParseDuration(offset)
+		if err != nil || duration <= 0 {
+			log.Fatalf("invalid reminder offset %q", offset)
+		}
+		reminderOffsets = append(reminderOffsets, duration)
+	}
+
+	authSecret := []byte(viperPkg.GetString("auth.secret"))
+	if len(authSecret) == 0 {
+		// a run without a secret gets one of its own, its tokens don't outlive it nor are they valid on
+		// another instance
+		authSecret = make([]byte, minAuthSecret)
+		if _, err := rand.Read(authSecret); err != nil {
+			log.Fatal(err)
+		}
+		zap.Warn("auth.secret isn't set, tokens are signed by a random secret : they are invalid after a restart " +
+			"and on any other instance, set auth.secret in production")
+	}
+	if len(authSecret) < minAuthSecret || string(authSecret) == placeholderAuthSecret {
+		log.Fatalf("auth.secret must be set to a random secret of at least %d bytes", minAuthSecret)
+	}
+
 	rh := &baseApp.Handlers{
+		Storage:  storage,
 		Postgres: pg,
+		SQLite:   lite,
+		Redis:    rdb,
+		CacheTTL: viperPkg.GetDuration("redis.cache.ttl"),
 		R:        app,
 		Logger:   zap,
+
+		ReminderOffsets: reminderOffsets,
+		ReminderRetry: remindersvc.Retry{
+			Backoff:     viperPkg.GetDuration("reminder.backoff"),
+			MaxAttempts: viperPkg.GetInt("reminder.max_attempts"),
+			Timeout:     viperPkg.GetDuration("reminder.timeout"),
+		},
+		WebhookRetry: webhooksvc.Retry{
+			Backoff:     viperPkg.GetDuration("webhook.backoff"),
+			MaxAttempts: viperPkg.GetInt("webhook.max_attempts"),
+			Timeout:     viperPkg.GetDuration("webhook.timeout"),
+		},
+		Tokens: authsvc.Tokens{
+			Secret:     authSecret,
+			Issuer:     viperPkg.GetString("auth.issuer"),
+			AccessTTL:  viperPkg.GetDuration("auth.access_ttl"),
+			RefreshTTL: viperPkg.GetDuration("auth.refresh_ttl"),
+		},
+		Outbox: baseApp.Outbox{
+			Publisher:   viperPkg.GetString("outbox.publisher"),
+			Redis:       outboxRdb,
+			Stream:      viperPkg.GetString("outbox.redis.stream"),
+			MaxLen:      viperPkg.GetInt64("outbox.redis.max_len"),
+			URL:         viperPkg.GetString("outbox.http.url"),
+			Timeout:     viperPkg.GetDuration("outbox.timeout"),
+			Backoff:     viperPkg.GetDuration("outbox.backoff"),
+			MaxAttempts: viperPkg.GetInt("outbox.max_attempts"),
+		},
 	}
 	rh.SetupRouter()
 
+	// run the background jobs until shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	workers := rh.StartWorkers(ctx, baseApp.Workers{
+		RecurrenceInterval: viperPkg.GetDuration("recurrence.interval"),
+		ReminderInterval:   viperPkg.GetDuration("reminder.interval"),
+		WebhookInterval:    viperPkg.GetDuration("webhook.interval"),
+		OutboxInterval:     viperPkg.GetDuration("outbox.interval"),
+	})
+
 	// Listen from a different goroutine
 	go func() {
 		if err := app.Listen(":" + viperPkg.GetString("server.port")); err != nil {
@@ -84,9 +223,17 @@ func Run() {
 	log.Println("gracefully shutting down...")
 	_ = app.Shutdown()
 
+	cancel()
+	workers.Wait()
+
 	fmt.Println("Running cleanup tasks...")
 
 	// Your cleanup tasks go here
-	sqlDB.Close()
+	if sqlDB != nil {
+		sqlDB.Close()
+	}
+	if rdb != nil {
+		rdb.Close()
+	}
 	fmt.Println("services was successful shutdown.")
 }
@@ -0,0 +1,66 @@
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+var ErrTrailingData = errors.New("invalid character after top-level value")
+
+// Apply applies an RFC 7396 JSON merge patch to document and returns the patched document
+func Apply(document []byte, patch []byte) ([]byte, error) {
+	target, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merge(target, changes))
+}
+
+// merge follows the MergePatch pseudo code of RFC 7396 section 2
+func merge(target interface{}, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = map[string]interface{}{}
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+
+		object[name] = merge(object[name], value)
+	}
+
+	return object
+}
+
+func decode(raw []byte) (interface{}, error) {
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	// a document is a single value, anything following it is rejected
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, ErrTrailingData
+	}
+
+	return value, nil
+}
@@ -0,0 +1,57 @@
+package mergepatch
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// TestApply runs the examples of RFC 7396 appendix A
+func TestApply(t *testing.T) {
+	cases := []struct {
+		document string
+		patch    string
+		result   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, c := range cases {
+		result, err := Apply([]byte(c.document), []byte(c.patch))
+		require.NoError(t, err, c.patch)
+		assert.JSONEq(t, c.result, string(result), c.patch)
+	}
+}
+
+func TestApplyKeepsNumbers(t *testing.T) {
+	result, err := Apply([]byte(`{"Action_Time":1792320674123456789}`), []byte(`{"Title":"a"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Action_Time":1792320674123456789,"Title":"a"}`, string(result))
+}
+
+func TestApplyRejectsInvalidPatch(t *testing.T) {
+	for _, patch := range []string{`{"Title":"a"} garbage`, `{"Title":"a"}{}`, `{"Title":`, ``} {
+		_, err := Apply([]byte(`{"Title":"b"}`), []byte(patch))
+		assert.Error(t, err, patch)
+	}
+
+	_, err := Apply([]byte(`{"Title":"b"}`), []byte(`{"Title":"a"} garbage`))
+	assert.ErrorIs(t, err, ErrTrailingData)
+
+	_, err = Apply([]byte(`{"Title":"b"}`), []byte(" {\"Title\":\"a\"}\n"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,19 @@
+package sqlite
+
+import (
+	"github.com/glebarez/sqlite"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Connect() (*gorm.DB, error) {
+	dsn := viper.GetString("sqlite.path") + "?_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"bufio"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// sqliteDirective starts the comments marking the dialect of the following lines, sql-migrate
+	// runs the files as they are
+	sqliteDirective = "-- +sqlite"
+	sqliteSkip      = "skip"
+	sqliteOnly      = "only"
+)
+
+// Migrate runs the "+migrate Up" section of every sql file in dir that is not yet recorded in the
+// migrations table. The files are the postgres migrations applied by sql-migrate, they are translated
+// to sqlite by upSection.
+func Migrate(db *gorm.DB, dir string) error {
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS migrations (id TEXT PRIMARY KEY, applied_at DATETIME)`).Error; err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		id := filepath.Base(file)
+
+		var applied int64
+		if err := db.Table("migrations").Where("id = ?", id).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			// a postgres only migration is recorded without running anything
+			if up := upSection(string(content)); strings.TrimSpace(up) != "" {
+				if err := tx.Exec(up).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Exec(`INSERT INTO migrations (id, applied_at) VALUES (?, ?)`, id, time.Now().UTC()).Error
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upSection returns the statements between "+migrate Up" and "+migrate Down" in the sqlite dialect.
+// BIGSERIAL keys become sqlite rowid keys, the lines between "+sqlite skip" and "+sqlite end" are
+// postgres only and the commented out lines between "+sqlite only" and "+sqlite end" are sqlite only.
+func upSection(content string) string {
+	var (
+		up    strings.Builder
+		isUp  bool
+		block string
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "-- +migrate Up"):
+			isUp = true
+			continue
+		case strings.HasPrefix(trimmed, "-- +migrate Down"):
+			isUp = false
+			continue
+		case strings.HasPrefix(trimmed, "-- +migrate"):
+			continue
+		case strings.HasPrefix(trimmed, sqliteDirective):
+			block = strings.TrimSpace(strings.TrimPrefix(trimmed, sqliteDirective))
+			continue
+		}
+
+		switch {
+		case !isUp || block == sqliteSkip:
+			continue
+		case block == sqliteOnly:
+			line = strings.TrimSpace(strings.TrimPrefix(trimmed, "--"))
+		}
+
+		up.WriteString(strings.ReplaceAll(line, "BIGSERIAL PRIMARY KEY", "INTEGER PRIMARY KEY AUTOINCREMENT"))
+		up.WriteString("\n")
+	}
+
+	return up.String()
+}
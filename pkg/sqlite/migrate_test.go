@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpSection(t *testing.T) {
+	content := `-- +migrate Up
+CREATE TABLE notes (
+    id BIGSERIAL PRIMARY KEY
+);
+-- +sqlite skip
+CREATE INDEX notes_idx ON notes USING GIN (id);
+-- +sqlite end
+-- +sqlite only
+-- CREATE INDEX notes_idx ON notes (id);
+-- +sqlite end
+
+-- +migrate Down
+DROP TABLE notes;
+`
+
+	assert.Equal(t, `CREATE TABLE notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT
+);
+CREATE INDEX notes_idx ON notes (id);
+
+`, upSection(content))
+}
+
+func TestMigrate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)"), &gorm.Config{})
+	require.NoError(t, err)
+
+	dir := filepath.Join("..", "..", "cmd", "migration")
+	require.NoError(t, Migrate(db, dir))
+	// the applied migrations are recorded, running them again does nothing
+	require.NoError(t, Migrate(db, dir))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	require.NoError(t, err)
+
+	var applied int64
+	require.NoError(t, db.Table("migrations").Count(&applied).Error)
+	assert.Equal(t, int64(len(files)), applied)
+
+	// the postgres only search vector isn't created
+	assert.True(t, db.Migrator().HasTable("tasks"))
+	assert.True(t, db.Migrator().HasColumn("tasks", "owner_id"))
+	assert.False(t, db.Migrator().HasColumn("tasks", "search_vector"))
+}